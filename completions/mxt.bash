@@ -34,6 +34,9 @@ _mxt_managed_branches() {
         [[ -n "$val" ]] && worktree_dir="$val"
     fi
 
+    # MXT_WORKTREE_DIR overrides both
+    [[ -n "$MXT_WORKTREE_DIR" ]] && worktree_dir="$MXT_WORKTREE_DIR"
+
     worktree_dir="${worktree_dir/#\~/$HOME}"
     repo_name=$(basename "$repo_root")
     wt_base="$worktree_dir/$repo_name"
@@ -52,16 +55,38 @@ _mxt_git_branches() {
     git branch -a --format='%(refname:short)' 2>/dev/null
 }
 
+_mxt_local_branches() {
+    git branch --format='%(refname:short)' 2>/dev/null
+}
+
+_mxt_profiles() {
+    local config_dir="${MXT_CONFIG_DIR:-$HOME/.config/mxt}"
+    local files=("$config_dir/config.toml") repo_root
+    repo_root=$(git rev-parse --show-toplevel 2>/dev/null) && files+=("$repo_root/.mxt.toml")
+    sed -n 's/^[[:space:]]*\[profiles\.\([A-Za-z0-9_-]*\).*/\1/p' "${files[@]}" 2>/dev/null | sort -u
+}
+
 _mxt() {
     local cur prev words cword
     _init_completion || return
 
-    local commands="init config new list ls delete rm sessions s help version"
+    local commands="init config new list ls delete rm rename mv adopt lock unlock migrate-dir diff finish sessions s jump doctor trust help version"
     local session_actions="open launch start close kill stop relaunch restart attach"
 
+    # Value for a global --set/-c override
+    if [[ "$prev" == "--set" || "$prev" == "-c" ]]; then
+        compopt -o nospace 2>/dev/null
+        COMPREPLY=($(compgen -W "worktree_dir= terminal= sandbox_tool= pre_session_cmd= pre_session_mode= pre_session_timeout= remote= base_branch= fetch_before_new= init_submodules= lfs_pull= copy_files= copy_ignored= tmux_layout= sparse_paths=" -- "$cur"))
+        return
+    fi
+
     # Top-level command completion
     if [[ $cword -eq 1 ]]; then
-        COMPREPLY=($(compgen -W "$commands" -- "$cur"))
+        if [[ "$cur" == -* ]]; then
+            COMPREPLY=($(compgen -W "--set -c" -- "$cur"))
+        else
+            COMPREPLY=($(compgen -W "$commands" -- "$cur"))
+        fi
         return
     fi
 
@@ -69,11 +94,55 @@ _mxt() {
 
     case "$cmd" in
         init)
-            COMPREPLY=($(compgen -W "--local -l --reinit" -- "$cur"))
+            case "$prev" in
+                --worktree-dir)
+                    COMPREPLY=($(compgen -d -- "$cur"))
+                    ;;
+                --terminal)
+                    COMPREPLY=($(compgen -W "terminal iterm2 ghostty current" -- "$cur"))
+                    ;;
+                --sandbox-tool|--copy-files|--pre-session-cmd|--tmux-layout)
+                    ;;
+                *)
+                    COMPREPLY=($(compgen -W "--local -l --reinit --yes -y --worktree-dir --terminal --sandbox-tool --copy-files --pre-session-cmd --tmux-layout" -- "$cur"))
+                    ;;
+            esac
+            ;;
+        config)
+            local config_keys="worktree_dir terminal sandbox_tool pre_session_cmd pre_session_mode pre_session_timeout remote base_branch default_profile fetch_before_new init_submodules lfs_pull copy_files copy_ignored tmux_layout sparse_paths"
+            local stage
+            for stage in post_create pre_delete post_delete on_session_open; do
+                config_keys+=" hooks.$stage hooks.$stage.on_failure"
+            done
+            if [[ "$prev" == --profile ]]; then
+                COMPREPLY=($(compgen -W "$(_mxt_profiles)" -- "$cur"))
+            elif [[ "$prev" == --branch ]]; then
+                COMPREPLY=($(compgen -W "$(_mxt_local_branches)" -- "$cur"))
+            elif [[ "$cur" == -* && "${words[2]}" == --effective ]]; then
+                COMPREPLY=($(compgen -W "--branch --profile" -- "$cur"))
+            elif [[ "$cur" == -* && $cword -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "--effective" -- "$cur"))
+            elif [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "--local -l" -- "$cur"))
+            elif [[ $cword -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "get set unset edit" -- "$cur"))
+            elif [[ $cword -eq 3 && "${words[2]}" =~ ^(get|set|unset)$ ]]; then
+                COMPREPLY=($(compgen -W "$config_keys" -- "$cur"))
+            fi
             ;;
-        config|list|ls|help|version)
+        trust)
+            if [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "--revoke" -- "$cur"))
+            fi
+            ;;
+        jump|doctor|help|version)
             # No further completions
             ;;
+        list|ls)
+            if [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "--all-worktrees --global -g" -- "$cur"))
+            fi
+            ;;
         new)
             case "$prev" in
                 --from)
@@ -84,9 +153,84 @@ _mxt() {
                 --run)
                     COMPREPLY=($(compgen -W "claude codex" -- "$cur"))
                     ;;
+                --sparse)
+                    COMPREPLY=($(compgen -d -- "$cur"))
+                    ;;
+                --profile)
+                    COMPREPLY=($(compgen -W "$(_mxt_profiles)" -- "$cur"))
+                    ;;
+                *)
+                    if [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--from --run --sparse --profile --bg --dry-run" -- "$cur"))
+                    fi
+                    ;;
+            esac
+            ;;
+        rename|mv)
+            if [[ $cword -eq 2 ]]; then
+                local branches
+                branches=$(_mxt_managed_branches)
+                COMPREPLY=($(compgen -W "$branches" -- "$cur"))
+            fi
+            ;;
+        adopt)
+            if [[ $cword -eq 2 ]]; then
+                local branches
+                branches=$(_mxt_local_branches)
+                COMPREPLY=($(compgen -W "$branches" -- "$cur") $(compgen -d -- "$cur"))
+            fi
+            ;;
+        lock|unlock)
+            if [[ "$cur" == -* && "$cmd" == lock ]]; then
+                COMPREPLY=($(compgen -W "--reason" -- "$cur"))
+            elif [[ $cword -eq 2 ]]; then
+                local branches
+                branches=$(_mxt_managed_branches)
+                COMPREPLY=($(compgen -W "$branches" -- "$cur"))
+            fi
+            ;;
+        migrate-dir)
+            case "$prev" in
+                --from)
+                    COMPREPLY=($(compgen -d -- "$cur"))
+                    ;;
+                *)
+                    COMPREPLY=($(compgen -W "--from --dry-run" -- "$cur"))
+                    ;;
+            esac
+            ;;
+        diff)
+            case "$prev" in
+                --tool)
+                    local tools
+                    tools=$(git difftool --tool-help 2>/dev/null | awk '/^\t\t[a-z]/ {print $1}')
+                    COMPREPLY=($(compgen -W "$tools" -- "$cur"))
+                    ;;
                 *)
                     if [[ "$cur" == -* ]]; then
-                        COMPREPLY=($(compgen -W "--from --run --bg" -- "$cur"))
+                        COMPREPLY=($(compgen -W "--stat --name-only --tool" -- "$cur"))
+                    else
+                        local branches
+                        branches=$(_mxt_managed_branches)
+                        COMPREPLY=($(compgen -W "$branches" -- "$cur"))
+                    fi
+                    ;;
+            esac
+            ;;
+        finish)
+            case "$prev" in
+                --into)
+                    local branches
+                    branches=$(_mxt_git_branches)
+                    COMPREPLY=($(compgen -W "$branches" -- "$cur"))
+                    ;;
+                *)
+                    if [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--merge --squash --rebase --into --force -f" -- "$cur"))
+                    else
+                        local branches
+                        branches=$(_mxt_managed_branches)
+                        COMPREPLY=($(compgen -W "$branches" -- "$cur"))
                     fi
                     ;;
             esac
@@ -125,16 +269,19 @@ _mxt() {
                         --run)
                             COMPREPLY=($(compgen -W "claude codex" -- "$cur"))
                             ;;
+                        --profile)
+                            COMPREPLY=($(compgen -W "$(_mxt_profiles)" -- "$cur"))
+                            ;;
                         *)
                             if [[ "$cur" == -* ]]; then
-                                COMPREPLY=($(compgen -W "--run --bg" -- "$cur"))
+                                COMPREPLY=($(compgen -W "--run --profile --bg" -- "$cur"))
                             fi
                             ;;
                     esac
                     ;;
                 attach)
                     if [[ $cword -eq 4 ]]; then
-                        COMPREPLY=($(compgen -W "dev agent" -- "$cur"))
+                        COMPREPLY=($(compgen -W "dev agent setup" -- "$cur"))
                     fi
                     ;;
             esac
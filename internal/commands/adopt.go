@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/hooks"
+	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+// AdoptCommand brings an existing worktree or branch under mxt management.
+//
+// target is either the path of a worktree created outside mxt, or a branch
+// name. A worktree is moved to the path mxt computes for its branch; a branch
+// with no worktree gets a new one there (with copy_files and the post_create
+// hook, as for mxt new). Afterwards all mxt commands work with the branch.
+func AdoptCommand(target string) error {
+	// Step 1: Prerequisite checks
+	if !git.IsInsideWorkTree() {
+		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+
+	// Step 2: Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	repoName, err := git.GetRepoName()
+	if err != nil {
+		return fmt.Errorf("failed to get repository name: %w", err)
+	}
+	mainCheckout, err := git.GetMainWorktree()
+	if err != nil {
+		return fmt.Errorf("failed to locate main checkout: %w", err)
+	}
+
+	// Step 3: Resolve the target to a worktree and/or branch
+	entries, err := git.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	entry, found, err := resolveAdoptTarget(target, entries)
+	if err != nil {
+		return err
+	}
+	branch := entry.Branch
+
+	// Step 4: Validate
+	if found {
+		if samePath(entry.Path, mainCheckout) {
+			return fmt.Errorf("'%s' is the main checkout, which can't be moved. Check out another branch there, then run mxt adopt %s.", entry.Path, branch)
+		}
+		if branch == "" {
+			return fmt.Errorf("Worktree %s has a detached HEAD. Check out a branch in it first.", entry.Path)
+		}
+	} else if !git.RefExists("refs/heads/"+branch) && !git.RefExists("refs/remotes/"+cfg.Remote+"/"+branch) {
+		return fmt.Errorf("'%s' is neither a worktree of this repository nor an existing branch.", target)
+	}
+
+	// Apply the branch's rules, and the profile recorded in the worktree if any
+	profile := ""
+	if found {
+		profile, _ = worktree.ReadProfile(entry.Path)
+	}
+	cfg, err = config.LoadFor(branch, profile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	worktreePath := git.CalculateWorktreePath(cfg.WorktreeDir, repoName, branch)
+	if found && samePath(entry.Path, worktreePath) {
+		ui.Info(fmt.Sprintf("%s is already managed by mxt (%s)", ui.CyanText(branch), worktreePath))
+		return nil
+	}
+	if _, err := os.Stat(worktreePath); err == nil {
+		return fmt.Errorf("Path already exists: %s", worktreePath)
+	}
+
+	// Step 5: Move the worktree, or create one for the branch
+	if found {
+		ui.Info(fmt.Sprintf("Moving worktree %s → %s", entry.Path, worktreePath))
+		if err := worktree.Move(entry.Path, worktreePath); err != nil {
+			return err
+		}
+		ui.Success("Worktree moved")
+	} else {
+		if err := worktree.AddExisting(worktreePath, branch); err != nil {
+			return err
+		}
+
+		if len(cfg.CopyFiles) > 0 || len(cfg.CopyIgnored) > 0 {
+			plan, err := worktree.BuildCopyPlan(mainCheckout, cfg.CopyFiles, cfg.CopyIgnored)
+			if err != nil {
+				ui.Warn(fmt.Sprintf("Could not select ignored files: %v", err))
+			}
+			if err := worktree.CopyFiles(mainCheckout, worktreePath, plan); err != nil {
+				ui.Warn(fmt.Sprintf("Some files could not be copied: %v", err))
+			}
+		}
+
+		hookContext := hooks.Context{
+			RepoName:     repoName,
+			RepoRoot:     mainCheckout,
+			Branch:       branch,
+			WorktreePath: worktreePath,
+			SessionName:  git.GenerateSessionName(repoName, branch),
+		}
+		if err := runHook(cfg, hooks.PostCreate, worktreePath, hookContext); err != nil {
+			return err
+		}
+	}
+
+	// Step 6: Success message
+	fmt.Println()
+	ui.Success(fmt.Sprintf("Adopted %s", ui.CyanText(branch)))
+	fmt.Printf("  Path:      %s\n", ui.DimText(worktreePath))
+	ui.Info(fmt.Sprintf("Start a session with %s", ui.BoldText("mxt sessions open "+branch)))
+
+	return nil
+}
+
+// resolveAdoptTarget finds the worktree that target names, either by the
+// branch checked out in it or by its path. If neither matches and target is
+// not an existing directory, it is returned as a branch name with found set
+// to false.
+func resolveAdoptTarget(target string, entries []git.Worktree) (git.Worktree, bool, error) {
+	for _, entry := range entries {
+		if entry.Branch == target {
+			return entry, true, nil
+		}
+	}
+
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		for _, entry := range entries {
+			if samePath(entry.Path, target) {
+				return entry, true, nil
+			}
+		}
+		return git.Worktree{}, false, fmt.Errorf("%s is not the root of a worktree of this repository.", target)
+	}
+	return git.Worktree{Branch: target}, false, nil
+}
+
+// samePath reports whether two paths refer to the same location, after
+// making them absolute and resolving symlinks.
+func samePath(a, b string) bool {
+	return canonicalPath(a) == canonicalPath(b)
+}
+
+func canonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
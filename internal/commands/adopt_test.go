@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/git"
+)
+
+func TestIsWithinDir(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/wt/app/feature", true},
+		{"/wt/app/nested/feature", true},
+		{"/wt/app", false},
+		{"/wt/app-other/feature", false},
+		{"/src/app", false},
+	}
+	for _, tt := range tests {
+		if got := isWithinDir(tt.path, "/wt/app"); got != tt.expected {
+			t.Errorf("isWithinDir(%q, \"/wt/app\") = %v, want %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestResolveAdoptTarget(t *testing.T) {
+	base := t.TempDir()
+	mainPath := filepath.Join(base, "app")
+	hotfixPath := filepath.Join(base, "app-hotfix")
+	otherDir := filepath.Join(base, "not-a-worktree")
+	for _, dir := range []string{mainPath, hotfixPath, otherDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := []git.Worktree{
+		{Path: mainPath, Branch: "main"},
+		{Path: hotfixPath, Branch: "hotfix"},
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		expected  git.Worktree
+		found     bool
+		expectErr bool
+	}{
+		{"branch with worktree", "hotfix", entries[1], true, false},
+		{"worktree path", hotfixPath, entries[1], true, false},
+		{"unclean worktree path", hotfixPath + "/../app-hotfix/", entries[1], true, false},
+		{"branch without worktree", "review-123", git.Worktree{Branch: "review-123"}, false, false},
+		{"directory that is not a worktree", otherDir, git.Worktree{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, found, err := resolveAdoptTarget(tt.target, entries)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("resolveAdoptTarget() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if entry != tt.expected || found != tt.found {
+				t.Errorf("resolveAdoptTarget() = %#v, %v; want %#v, %v", entry, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
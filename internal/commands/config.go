@@ -3,6 +3,9 @@ package commands
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/gkarolyi/mxt/internal/config"
 	mxtErrors "github.com/gkarolyi/mxt/internal/errors"
@@ -27,6 +30,9 @@ func ConfigCommand() error {
 		}
 		fmt.Print(string(content))
 		fmt.Println()
+		if err := printIncludedConfigs(globalConfigPath); err != nil {
+			return err
+		}
 	}
 
 	projectExists := false
@@ -45,6 +51,9 @@ func ConfigCommand() error {
 			}
 			fmt.Print(string(content))
 			fmt.Println()
+			if err := printIncludedConfigs(projectConfigPath); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -59,3 +68,210 @@ func ConfigCommand() error {
 
 	return nil
 }
+
+// printIncludedConfigs prints each file the config at path includes, in the
+// order they are merged.
+func printIncludedConfigs(path string) error {
+	file, err := config.LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	for _, included := range file.Included {
+		fmt.Printf("%sIncluded config:%s %s %s(from %s)%s\n", ui.Bold, ui.Reset, included, ui.Dim, path, ui.Reset)
+		fmt.Println(separator)
+
+		content, err := os.ReadFile(included)
+		if err != nil {
+			return fmt.Errorf("failed to read included config: %w", err)
+		}
+		fmt.Print(string(content))
+		fmt.Println()
+	}
+	return nil
+}
+
+// ConfigEffectiveCommand prints the merged configuration LoadConfig uses,
+// one key per line, with the layer that supplied each value. branch applies
+// the [[rules]] matching it, and profile selects a profile as mxt new
+// --profile would; empty means default_profile.
+func ConfigEffectiveCommand(branch, profile string) error {
+	layers, err := config.LoadLayers(".", branch, profile)
+	if err != nil {
+		return err
+	}
+	settings, err := config.Effective(layers)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%sEffective config%s\n", ui.Bold, ui.Reset)
+	paths := map[string]string{}
+	for _, setting := range settings {
+		for _, layer := range append(setting.Added, setting.Layer) {
+			// Rules are labelled by their glob, and live in the files listed here
+			if layer.Path != "" && layer.Source != config.SourceRule {
+				paths[layer.Source] = layer.Path
+			}
+		}
+	}
+	for _, layer := range layers {
+		if path, ok := paths[layer.Source]; ok {
+			fmt.Printf("%s%-8s %s%s\n", ui.Dim, layer.Source+":", path, ui.Reset)
+		}
+	}
+	fmt.Println(separator)
+	for _, layer := range layers {
+		if layer.Untrusted {
+			ui.Warn(fmt.Sprintf("Commands in %s are ignored until you trust it with mxt trust", layer.Path))
+		}
+	}
+
+	width := 0
+	for _, setting := range settings {
+		width = max(width, len(setting.Key))
+	}
+	for _, setting := range settings {
+		// copy_files and copy_ignored list every layer that added entries
+		var sources []string
+		for _, layer := range append(setting.Added, setting.Layer) {
+			sources = append(sources, layerLabel(layer, setting.Key))
+		}
+		line := fmt.Sprintf("%-*s = %s", width, setting.Key, setting.Value)
+		fmt.Printf("%s  %s\n", line, ui.DimText("# "+strings.Join(sources, " + ")))
+	}
+
+	return nil
+}
+
+// layerLabel names the layer that supplied key in mxt config effective.
+func layerLabel(layer config.Layer, key string) string {
+	switch layer.Source {
+	case config.SourceEnv:
+		return layer.Source + " " + config.EnvVar(key)
+	case config.SourceProfile, config.SourceRule:
+		return layer.Source + " " + layer.Name
+	}
+	return layer.Source
+}
+
+// runEditor opens path in the user's editor. It is a variable so tests can stub it.
+var runEditor = func(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Run through the shell so editors with arguments (e.g. "code --wait") work
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// configFilePath returns the global config path, or the project config path
+// of the current repository when local is set.
+func configFilePath(local bool) (string, error) {
+	if !local {
+		return config.GetGlobalConfigPath(), nil
+	}
+	gitRoot, err := config.FindGitRoot(".")
+	if err != nil {
+		return "", fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+	return config.GetProjectConfigPath(gitRoot), nil
+}
+
+// ConfigGetCommand prints the value key has in the global (or --local) config
+// file. List values are printed one entry per line.
+func ConfigGetCommand(key string, local bool) error {
+	path, err := configFilePath(local)
+	if err != nil {
+		return err
+	}
+	if err := config.CheckKey(key); err != nil {
+		return err
+	}
+	_, file, err := config.ReadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	values, ok := file.Lookup(key)
+	if !ok {
+		return fmt.Errorf("%s is not set in %s", key, path)
+	}
+	for _, value := range values {
+		fmt.Println(value)
+	}
+	return nil
+}
+
+// ConfigSetCommand sets key in the global (or --local) config file, keeping
+// the file's comments and ordering.
+func ConfigSetCommand(key, value string, local bool) error {
+	path, err := configFilePath(local)
+	if err != nil {
+		return err
+	}
+	retrust := keepTrust(path, local)
+	if err := config.SetConfigValue(path, key, value); err != nil {
+		return err
+	}
+	retrust()
+	ui.Success(fmt.Sprintf("Set %s in %s", ui.BoldText(key), path))
+	return nil
+}
+
+// ConfigUnsetCommand removes key from the global (or --local) config file.
+func ConfigUnsetCommand(key string, local bool) error {
+	path, err := configFilePath(local)
+	if err != nil {
+		return err
+	}
+	retrust := keepTrust(path, local)
+	removed, err := config.UnsetConfigValue(path, key)
+	if err != nil {
+		return err
+	}
+	retrust()
+	if !removed {
+		ui.Info(fmt.Sprintf("%s is not set in %s", key, path))
+		return nil
+	}
+	ui.Success(fmt.Sprintf("Removed %s from %s", ui.BoldText(key), path))
+	return nil
+}
+
+// ConfigEditCommand opens the global (or --local) config file in $VISUAL or
+// $EDITOR, then checks that the result still loads.
+func ConfigEditCommand(local bool) error {
+	path, err := configFilePath(local)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	retrust := keepTrust(path, local)
+	if err := runEditor(path); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil // Editor quit without saving a new file
+	}
+	if _, err := config.LoadConfigFile(path); err != nil {
+		return fmt.Errorf("%v. Run mxt config edit%s again to fix it.", err, localFlagSuffix(local))
+	}
+	retrust()
+	ui.Success(fmt.Sprintf("Config at %s is valid", path))
+	return nil
+}
+
+func localFlagSuffix(local bool) string {
+	if local {
+		return " --local"
+	}
+	return ""
+}
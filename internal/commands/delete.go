@@ -10,12 +10,15 @@ import (
 
 	"github.com/gkarolyi/mxt/internal/config"
 	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/hooks"
 	"github.com/gkarolyi/mxt/internal/tmux"
 	"github.com/gkarolyi/mxt/internal/ui"
 	"github.com/gkarolyi/mxt/internal/worktree"
 )
 
 // DeleteCommand deletes a worktree, kills its tmux session, and removes the branch.
+// branch may also be the path of a managed worktree, e.g. one with a detached
+// HEAD, in which case there is no branch to delete.
 func DeleteCommand(branch string, force bool) error {
 	if !git.IsInsideWorkTree() {
 		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
@@ -31,31 +34,80 @@ func DeleteCommand(branch string, force bool) error {
 		return fmt.Errorf("failed to get repository name: %w", err)
 	}
 
-	worktreePath := git.CalculateWorktreePath(cfg.WorktreeDir, repoName, branch)
-	if _, err := os.Stat(worktreePath); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("Worktree not found: %s", worktreePath)
-		}
-		return fmt.Errorf("failed to access worktree: %w", err)
+	target, err := resolveWorktreeTarget(cfg.WorktreeDir, repoName, branch)
+	if err != nil {
+		return err
+	}
+	worktreePath := target.path
+	if err := checkNotLocked(worktreePath, branch); err != nil {
+		return err
+	}
+
+	// Hooks come from the branch's rules and the profile the worktree was created with
+	cfg, err = loadWorktreeConfig(target, "")
+	if err != nil {
+		return err
 	}
 
 	insertions, deletions := calculateChangeStats(worktreePath)
 
 	fmt.Println()
-	fmt.Printf("  Branch:    %s\n", ui.BoldText(branch))
+	if target.branch != "" {
+		fmt.Printf("  Branch:    %s\n", ui.BoldText(target.branch))
+	} else {
+		fmt.Printf("  Branch:    %s\n", ui.YellowText("(detached HEAD)"))
+	}
 	fmt.Printf("  Path:      %s\n", ui.DimText(worktreePath))
 	fmt.Printf("  Changes:   %s %s\n", ui.GreenText(fmt.Sprintf("+%d", insertions)), ui.RedText(fmt.Sprintf("-%d", deletions)))
 	fmt.Println()
 
 	if !force {
-		ui.Warn("This will remove the worktree and delete the local branch.")
+		if target.branch != "" {
+			ui.Warn("This will remove the worktree and delete the local branch.")
+		} else {
+			ui.Warn("This will remove the worktree.")
+		}
 		if !promptDeleteConfirm() {
 			ui.Info("Cancelled.")
 			return nil
 		}
 	}
 
-	sessionName := git.GenerateSessionName(repoName, branch)
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+	hookContext := hooks.Context{
+		RepoName:     repoName,
+		RepoRoot:     repoRoot,
+		Branch:       target.branch,
+		WorktreePath: worktreePath,
+		SessionName:  target.sessionName,
+	}
+
+	if err := removeManagedWorktree(cfg, hookContext); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	ui.Success("Done.")
+
+	return nil
+}
+
+// removeManagedWorktree runs the delete flow for a managed worktree: the
+// pre_delete hook, killing its tmux session, removing the worktree, deleting
+// the branch (unless hookContext.Branch is empty, for a detached worktree),
+// and the post_delete hook (run from hookContext.RepoRoot).
+func removeManagedWorktree(cfg *config.Config, hookContext hooks.Context) error {
+	worktreePath := hookContext.WorktreePath
+	branch := hookContext.Branch
+	sessionName := hookContext.SessionName
+
+	if err := runHook(cfg, hooks.PreDelete, worktreePath, hookContext); err != nil {
+		return err
+	}
+
 	if tmux.HasSession(sessionName, cfg.SandboxTool) {
 		if err := tmux.KillSession(sessionName, cfg.SandboxTool); err != nil {
 			return fmt.Errorf("failed to kill session %s: %w", sessionName, err)
@@ -69,19 +121,19 @@ func DeleteCommand(branch string, force bool) error {
 	}
 	ui.Success("Worktree removed")
 
-	ui.Info(fmt.Sprintf("Deleting branch %s...", ui.CyanText(branch)))
-	if err := git.DeleteBranch(branch); err != nil {
-		ui.Warn("Branch may have already been deleted")
-	} else {
-		ui.Success("Branch deleted")
+	if branch != "" {
+		ui.Info(fmt.Sprintf("Deleting branch %s...", ui.CyanText(branch)))
+		if err := git.DeleteBranch(branch); err != nil {
+			ui.Warn("Branch may have already been deleted")
+		} else {
+			ui.Success("Branch deleted")
+		}
 	}
 
-	cleanupRepoDir(cfg.WorktreeDir, repoName)
+	cleanupRepoDir(cfg.WorktreeDir, hookContext.RepoName)
 
-	fmt.Println()
-	ui.Success("Done.")
-
-	return nil
+	// The worktree is gone, so post_delete runs from the main checkout
+	return runHook(cfg, hooks.PostDelete, hookContext.RepoRoot, hookContext)
 }
 
 func promptDeleteConfirm() bool {
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
+	"golang.org/x/term"
+)
+
+// DiffCommand shows a managed worktree's changes against its merge-base with
+// the base branch (base_branch, or <remote>/HEAD), including uncommitted and
+// untracked files.
+//
+// format is worktree.DiffPatch, worktree.DiffStat or worktree.DiffNameOnly;
+// when tool is set, the changes are opened with git difftool instead.
+func DiffCommand(branch, format, tool string) error {
+	// Step 1: Prerequisite checks
+	if !git.IsInsideWorkTree() {
+		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+
+	// Step 2: Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if tool != "" && format != worktree.DiffPatch {
+		return fmt.Errorf("--tool cannot be combined with --%s", format)
+	}
+
+	// Step 3: Locate the worktree
+	repoName, err := git.GetRepoName()
+	if err != nil {
+		return fmt.Errorf("failed to get repository name: %w", err)
+	}
+	target, err := resolveWorktreeTarget(cfg.WorktreeDir, repoName, branch)
+	if err != nil {
+		return err
+	}
+	worktreePath := target.path
+
+	// base_branch may come from the branch's rules or the worktree's profile
+	cfg, err = loadWorktreeConfig(target, "")
+	if err != nil {
+		return err
+	}
+
+	// Step 4: Find the merge-base of the worktree's HEAD with the base branch
+	// (the target may be a path or a detached worktree, so not a branch name)
+	baseBranch := cfg.BaseBranch
+	if baseBranch == "" {
+		baseBranch = git.GetDefaultBranch(cfg.Remote)
+	}
+	if target.branch != "" && target.branch == baseBranch {
+		return fmt.Errorf("'%s' is the base branch; nothing to compare against.", target.branch)
+	}
+	head, err := git.ResolveCommit(worktreePath, "HEAD")
+	if err != nil {
+		return err
+	}
+	mergeBase, err := git.MergeBase(baseBranch, head)
+	if err != nil {
+		return err
+	}
+
+	// Step 5: Show the diff (header only for humans, so output stays pipeable)
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		name := target.branch
+		if name == "" {
+			name = "detached " + shortCommit(head)
+		}
+		ui.Info(fmt.Sprintf("%s vs %s %s", ui.CyanText(name), ui.CyanText(baseBranch), ui.DimText("(merge-base "+shortCommit(mergeBase)+", including uncommitted changes)")))
+	}
+	return worktree.Diff(worktreePath, mergeBase, format, tool)
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
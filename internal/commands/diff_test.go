@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+// TestDiffCommandDetachedByPath diffs a detached worktree named by its path,
+// which isn't a revision git merge-base understands.
+func TestDiffCommandDetachedByPath(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "app")
+	worktreeDir := filepath.Join(base, "wt")
+	scratchPath := filepath.Join(worktreeDir, "app", "scratch")
+	configDir := filepath.Join(base, "config")
+	t.Setenv("MXT_CONFIG_DIR", configDir)
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	globalConfig := fmt.Sprintf("worktree_dir = %q\nbase_branch = \"main\"\n", worktreeDir)
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(globalConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repoGit := testutil.NewRepo(t, repo)
+	repoGit("worktree", "add", "-q", "--detach", scratchPath)
+	if err := os.WriteFile(filepath.Join(scratchPath, "committed.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	testutil.RunGit(t, scratchPath, "add", "committed.txt")
+	testutil.RunGit(t, scratchPath, "commit", "-q", "-m", "committed")
+	if err := os.WriteFile(filepath.Join(scratchPath, "untracked.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := DiffCommand(scratchPath, worktree.DiffNameOnly, "")
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("DiffCommand() error = %v", err)
+	}
+
+	expected := "committed.txt\nuntracked.txt"
+	if got := strings.TrimSpace(string(out)); got != expected {
+		t.Errorf("DiffCommand() output = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,399 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/sandbox"
+	"github.com/gkarolyi/mxt/internal/ui"
+)
+
+// Minimum git versions: worktree move/remove (rename, delete) need 2.17,
+// worktree repair (migrate-dir) needs 2.30.
+var (
+	minGitVersion       = [2]int{2, 17}
+	minGitRepairVersion = [2]int{2, 30}
+)
+
+// sandboxCheckTimeout bounds how long doctor waits for sandbox_tool to run.
+const sandboxCheckTimeout = 30 * time.Second
+
+var (
+	lookPath      = exec.LookPath
+	commandOutput = func(name string, args ...string) (string, error) {
+		output, err := exec.Command(name, args...).Output()
+		return strings.TrimSpace(string(output)), err
+	}
+)
+
+// doctorReport prints check results as they happen and counts problems
+// (which make mxt doctor fail) and warnings (which don't).
+type doctorReport struct {
+	problems int
+	warnings int
+}
+
+func (r *doctorReport) ok(msg string) {
+	ui.Success(msg)
+}
+
+func (r *doctorReport) info(msg string) {
+	ui.Info(msg)
+}
+
+func (r *doctorReport) warn(msg string) {
+	r.warnings++
+	ui.Warn(msg)
+}
+
+func (r *doctorReport) fail(msg string) {
+	r.problems++
+	ui.Error(msg)
+}
+
+func (r *doctorReport) section(title string) {
+	fmt.Println()
+	fmt.Printf("%s%s%s\n", ui.Bold, title, ui.Reset)
+}
+
+// DoctorCommand checks the tools mxt depends on, the config files and the
+// worktrees under $WORKTREE_DIR, so problems show up before mxt new hits
+// them. It returns an error when any check fails, for use in CI.
+func DoctorCommand() error {
+	report := &doctorReport{}
+	fmt.Printf("%smxt doctor%s\n", ui.Bold, ui.Reset)
+	fmt.Println("════════════════════════════════════════════════════════════════")
+
+	// Step 1: Tools
+	report.section("Tools")
+	checkGit(report)
+	checkTmux(report)
+	if _, err := lookPath("fzf"); err != nil {
+		report.warn("fzf not found (needed by mxt jump and the mxt sessions picker)")
+	} else {
+		report.ok("fzf found")
+	}
+
+	// Step 2: Config files and overrides (reporting trust rather than asking)
+	report.section("Config")
+	config.SetTrustPrompt(nil)
+	cfg := checkConfig(report)
+
+	// Step 3: Settings that only fail once mxt new uses them
+	if cfg != nil {
+		checkTerminal(report, cfg.Terminal)
+		if err := checkWritableDir(cfg.WorktreeDir); err != nil {
+			report.fail(fmt.Sprintf("worktree_dir %s is not writable: %v", cfg.WorktreeDir, err))
+		} else {
+			report.ok(fmt.Sprintf("worktree_dir %s is writable", cfg.WorktreeDir))
+		}
+		checkTmuxLayout(report, cfg)
+		checkSandboxTool(report, cfg.SandboxTool)
+	}
+
+	// Step 4: Worktrees
+	if cfg != nil {
+		report.section("Worktrees")
+		checkWorktrees(report, cfg.WorktreeDir)
+	}
+
+	fmt.Println()
+	if report.problems > 0 {
+		return fmt.Errorf("Found %d problem(s) and %d warning(s)", report.problems, report.warnings)
+	}
+	if report.warnings > 0 {
+		ui.Warn(fmt.Sprintf("No problems found, %d warning(s)", report.warnings))
+		return nil
+	}
+	ui.Success("No problems found")
+	return nil
+}
+
+func checkGit(report *doctorReport) {
+	output, err := commandOutput("git", "--version")
+	if err != nil {
+		report.fail("git not found")
+		return
+	}
+	version, ok := parseVersion(output)
+	switch {
+	case !ok:
+		report.warn(fmt.Sprintf("Could not read the git version from %q", output))
+	case !versionAtLeast(version, minGitVersion):
+		report.fail(fmt.Sprintf("%s is too old: mxt needs git %d.%d or newer for worktree move and remove", output, minGitVersion[0], minGitVersion[1]))
+	case !versionAtLeast(version, minGitRepairVersion):
+		report.warn(fmt.Sprintf("%s: mxt migrate-dir needs git %d.%d or newer for worktree repair", output, minGitRepairVersion[0], minGitRepairVersion[1]))
+	default:
+		report.ok(fmt.Sprintf("%s (worktree support)", output))
+	}
+}
+
+func checkTmux(report *doctorReport) {
+	output, err := commandOutput("tmux", "-V")
+	if err != nil {
+		report.fail("tmux not found (every mxt session runs in tmux)")
+		return
+	}
+	report.ok(output)
+}
+
+// checkConfig validates each config file on its own, so every broken file
+// is reported, then the environment and --set overrides. It returns the
+// merged config, or nil if it can't be loaded.
+func checkConfig(report *doctorReport) *config.Config {
+	paths := []string{config.GetGlobalConfigPath()}
+	if gitRoot, err := config.FindGitRoot("."); err == nil {
+		paths = append(paths, config.GetProjectConfigPath(gitRoot))
+	}
+
+	valid := true
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			report.info(fmt.Sprintf("%s not found (using defaults)", path))
+			continue
+		}
+		if _, err := config.LoadConfigFile(path); err != nil {
+			report.fail(err.Error())
+			valid = false
+			continue
+		}
+		report.ok(path)
+	}
+	if !valid {
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		report.fail(err.Error())
+		return nil
+	}
+	if len(paths) > 1 {
+		checkTrust(report, paths[1], cfg)
+	}
+	return cfg
+}
+
+// checkTrust reports whether the commands a project config sets apply. The
+// merged cfg leaves out an untrusted file's tmux_layout, so that is checked
+// here, as it would apply once trusted.
+func checkTrust(report *doctorReport, path string, cfg *config.Config) {
+	file, err := config.LoadConfigFile(path)
+	if err != nil || len(config.CommandSettings(file)) == 0 {
+		return
+	}
+	trusted, err := config.IsTrusted(path)
+	switch {
+	case err != nil:
+		report.fail(err.Error())
+	case trusted:
+		report.ok(fmt.Sprintf("Commands in %s are trusted", path))
+	default:
+		report.warn(fmt.Sprintf("Commands in %s are ignored because it is not trusted. Run mxt trust.", path))
+		if len(file.TmuxLayout) == 0 {
+			return
+		}
+		trustedCfg := *cfg
+		trustedCfg.TmuxLayout = file.TmuxLayout
+		if file.PreSessionCmd != nil {
+			trustedCfg.PreSessionCmd = *file.PreSessionCmd
+		}
+		if _, err := validateLayout(&trustedCfg); err != nil {
+			report.fail(fmt.Sprintf("tmux_layout in %s is invalid: %v", path, err))
+			return
+		}
+		report.info(fmt.Sprintf("tmux_layout in %s is valid but ignored until trusted", path))
+	}
+}
+
+func checkTerminal(report *doctorReport, terminal string) {
+	tool, err := terminalRequirement(terminal)
+	if err != nil {
+		report.fail(err.Error())
+		return
+	}
+	if tool == "" {
+		report.ok(fmt.Sprintf("terminal %q needs no helper", terminal))
+		return
+	}
+	if _, err := lookPath(tool); err != nil {
+		report.fail(fmt.Sprintf("terminal %q needs %s, which was not found (use terminal = \"current\" outside macOS)", terminal, tool))
+		return
+	}
+	report.ok(fmt.Sprintf("terminal %q (%s found)", terminal, tool))
+}
+
+// terminalRequirement returns the command terminal.Open runs to open a
+// window for terminalType, or "" when it attaches in the current terminal.
+func terminalRequirement(terminalType string) (string, error) {
+	switch terminalType {
+	case "terminal", "", "iterm2":
+		return "osascript", nil
+	case "ghostty":
+		return "open", nil
+	case "current":
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown terminal %q (use terminal, iterm2, ghostty, or current)", terminalType)
+	}
+}
+
+// checkWritableDir reports whether worktrees can be created in dir: it must
+// be a writable directory, or not exist yet under a writable one.
+func checkWritableDir(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return err
+		}
+		existing = parent
+	}
+
+	probe, err := os.CreateTemp(existing, ".mxt-doctor-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// checkTmuxLayout checks tmux_layout the way mxt new does, taking
+// pre_session_cmd and pre_session_mode into account.
+func checkTmuxLayout(report *doctorReport, cfg *config.Config) {
+	if len(cfg.TmuxLayout) == 0 {
+		report.ok("tmux_layout not set (default dev and agent windows)")
+		return
+	}
+	windows, err := validateLayout(cfg)
+	if err != nil {
+		report.fail(fmt.Sprintf("tmux_layout is invalid: %v", err))
+		return
+	}
+	report.ok(fmt.Sprintf("tmux_layout parses into %d window(s)", len(windows)))
+}
+
+// checkSandboxTool runs tmux -V through sandbox_tool, which checks both that
+// the sandbox starts and that tmux is available inside it.
+func checkSandboxTool(report *doctorReport, sandboxTool string) {
+	if sandboxTool == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sandboxCheckTimeout)
+	defer cancel()
+	cmd := sandbox.CommandContext(ctx, sandboxTool, "tmux", "-V")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		detail := strings.TrimSpace(string(output))
+		if detail == "" {
+			detail = err.Error()
+		}
+		report.fail(fmt.Sprintf("sandbox_tool %q could not run tmux: %s", sandboxTool, detail))
+		return
+	}
+	report.ok(fmt.Sprintf("sandbox_tool %q runs tmux", sandboxTool))
+}
+
+// checkWorktrees reports worktrees git can't find anymore, directories under
+// $WORKTREE_DIR/<repo>/ that aren't worktrees, and worktrees anywhere under
+// $WORKTREE_DIR whose repository is gone. Locked worktrees are listed too,
+// since delete and migrate-dir leave them alone.
+func checkWorktrees(report *doctorReport, worktreeDir string) {
+	found := false
+	if git.IsInsideWorkTree() {
+		if entries, err := git.ListWorktrees(); err != nil {
+			report.fail(err.Error())
+		} else {
+			for _, entry := range entries {
+				if entry.Locked {
+					report.info(fmt.Sprintf("%s is locked%s", entry.Path, formatLockReason(entry.LockReason)))
+				}
+				if entry.Prunable {
+					found = true
+					report.warn(fmt.Sprintf("%s is missing (%s). Run git worktree prune.", entry.Path, entry.PrunableReason))
+				}
+			}
+			if repoName, err := git.GetRepoName(); err == nil {
+				for _, dir := range findStrayDirs(filepath.Join(worktreeDir, repoName)) {
+					found = true
+					report.warn(fmt.Sprintf("%s is not a git worktree (left over from an interrupted mxt new or delete?)", dir))
+				}
+			}
+		}
+	}
+
+	worktrees, err := scanGlobalWorktrees(worktreeDir)
+	if err != nil {
+		report.fail(err.Error())
+		return
+	}
+	for _, wt := range worktrees {
+		if wt.mainRepo == "" {
+			found = true
+			report.warn(fmt.Sprintf("%s has a broken .git link (its repository was moved or deleted)", wt.path))
+		}
+	}
+
+	if !found {
+		report.ok(fmt.Sprintf("%d worktree(s) under %s, none stale or broken", len(worktrees), worktreeDir))
+	}
+}
+
+// findStrayDirs returns the directories in managedBase that have no .git
+// link, so git doesn't know them as worktrees.
+func findStrayDirs(managedBase string) []string {
+	dirs, err := os.ReadDir(managedBase)
+	if err != nil {
+		return nil
+	}
+	var stray []string
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		path := filepath.Join(managedBase, dir.Name())
+		if _, err := os.Stat(filepath.Join(path, ".git")); os.IsNotExist(err) {
+			stray = append(stray, path)
+		}
+	}
+	return stray
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// parseVersion extracts the major and minor version from output such as
+// "git version 2.39.3 (Apple Git-145)".
+func parseVersion(output string) ([2]int, bool) {
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return [2]int{}, false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return [2]int{major, minor}, true
+}
+
+func versionAtLeast(version, minimum [2]int) bool {
+	if version[0] != minimum[0] {
+		return version[0] > minimum[0]
+	}
+	return version[1] >= minimum[1]
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/config"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected [2]int
+		ok       bool
+	}{
+		{"git version 2.43.0", [2]int{2, 43}, true},
+		{"git version 2.39.3 (Apple Git-145)", [2]int{2, 39}, true},
+		{"tmux 3.3a", [2]int{3, 3}, true},
+		{"tmux next-3.5", [2]int{3, 5}, true},
+		{"unknown", [2]int{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			got, ok := parseVersion(tt.output)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("parseVersion(%q) = %v, %v, want %v, %v", tt.output, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  [2]int
+		expected bool
+	}{
+		{[2]int{2, 17}, true},
+		{[2]int{2, 43}, true},
+		{[2]int{3, 0}, true},
+		{[2]int{2, 16}, false},
+		{[2]int{1, 99}, false},
+	}
+	for _, tt := range tests {
+		if got := versionAtLeast(tt.version, minGitVersion); got != tt.expected {
+			t.Errorf("versionAtLeast(%v, %v) = %v, want %v", tt.version, minGitVersion, got, tt.expected)
+		}
+	}
+}
+
+func TestTerminalRequirement(t *testing.T) {
+	tests := []struct {
+		terminal string
+		expected string
+		wantErr  bool
+	}{
+		{"terminal", "osascript", false},
+		{"iterm2", "osascript", false},
+		{"ghostty", "open", false},
+		{"current", "", false},
+		{"kitty", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.terminal, func(t *testing.T) {
+			got, err := terminalRequirement(tt.terminal)
+			if (err != nil) != tt.wantErr || got != tt.expected {
+				t.Errorf("terminalRequirement(%q) = %q, %v, want %q (error: %v)", tt.terminal, got, err, tt.expected, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckWritableDir(t *testing.T) {
+	base := t.TempDir()
+	file := filepath.Join(base, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	readOnly := filepath.Join(base, "read-only")
+	if err := os.Mkdir(readOnly, 0o555); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		dir        string
+		wantErr    bool
+		skipAsRoot bool // root can write to read-only directories
+	}{
+		{"existing directory", base, false, false},
+		{"not created yet", filepath.Join(base, "worktrees", "nested"), false, false},
+		{"file in the way", filepath.Join(file, "worktrees"), true, false},
+		{"read-only parent", filepath.Join(readOnly, "worktrees"), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.skipAsRoot && os.Geteuid() == 0 {
+				t.Skip("running as root")
+			}
+			err := checkWritableDir(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkWritableDir(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(base, "worktrees")); !os.IsNotExist(err) {
+		t.Errorf("checkWritableDir() created the missing directory")
+	}
+}
+
+func TestFindStrayDirs(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"feature-auth", "leftover"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "feature-auth", ".git"), []byte("gitdir: /src/app/.git/worktrees/feature-auth\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{filepath.Join(base, "leftover")}
+	if got := findStrayDirs(base); !reflect.DeepEqual(got, expected) {
+		t.Errorf("findStrayDirs() = %q, want %q", got, expected)
+	}
+	if got := findStrayDirs(filepath.Join(base, "missing")); got != nil {
+		t.Errorf("findStrayDirs(missing) = %q, want nil", got)
+	}
+}
+
+func TestCheckTrustUntrustedTmuxLayout(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantProblems int
+	}{
+		{"valid layout", "tmux_layout = [\"dev:hx\", \"agent:\"]\n", 0},
+		{"invalid layout", "tmux_layout = [\"dev hx\"]\n", 1},
+		{"setup window with its own pre_session_cmd", "pre_session_cmd = \"npm install\"\npre_session_mode = \"window\"\ntmux_layout = [\"setup:bin/setup\"]\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MXT_CONFIG_DIR", t.TempDir())
+			path := filepath.Join(t.TempDir(), ".mxt.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			// The merged config keeps pre_session_mode, which isn't a command
+			cfg := &config.Config{PreSessionMode: "window"}
+
+			report := &doctorReport{}
+			checkTrust(report, path, cfg)
+			if report.warnings != 1 {
+				t.Errorf("checkTrust() warnings = %d, want 1 (not trusted)", report.warnings)
+			}
+			if report.problems != tt.wantProblems {
+				t.Errorf("checkTrust() problems = %d, want %d", report.problems, tt.wantProblems)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/hooks"
+	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+// maxDirtyLines limits how many uncommitted paths are listed in errors.
+const maxDirtyLines = 10
+
+// FinishCommand integrates a worktree's branch into its base branch and then
+// deletes the worktree, session and branch.
+//
+// strategy is one of worktree.Strategies (merge, squash, rebase). into
+// overrides the base branch (default: base_branch, or <remote>/HEAD).
+// The branch is integrated where the base branch is checked out when that
+// checkout is clean; otherwise in a temporary worktree, after which the base
+// branch is fast-forwarded (or updated directly if it is not checked out).
+func FinishCommand(branch, strategy, into string, force bool) error {
+	// Step 1: Prerequisite checks
+	if !git.IsInsideWorkTree() {
+		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+
+	// Step 2: Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if strategy == "" {
+		strategy = worktree.StrategyMerge
+	}
+	if !worktree.IsStrategy(strategy) {
+		return fmt.Errorf("Invalid strategy: '%s'. Allowed: %s", strategy, strings.Join(worktree.Strategies, ", "))
+	}
+
+	repoName, err := git.GetRepoName()
+	if err != nil {
+		return fmt.Errorf("failed to get repository name: %w", err)
+	}
+	mainCheckout, err := git.GetMainWorktree()
+	if err != nil {
+		return fmt.Errorf("failed to locate main checkout: %w", err)
+	}
+
+	// Step 3: Validate the worktree and base branch
+	target, err := resolveWorktreeTarget(cfg.WorktreeDir, repoName, branch)
+	if err != nil {
+		return err
+	}
+	worktreePath := target.path
+	if err := checkNotLocked(worktreePath, branch); err != nil {
+		return err
+	}
+	if !git.RefExists("refs/heads/" + branch) {
+		return fmt.Errorf("Branch '%s' does not exist.", branch)
+	}
+
+	// Apply the branch's rules and the profile the worktree was created with
+	cfg, err = loadWorktreeConfig(target, "")
+	if err != nil {
+		return err
+	}
+
+	baseBranch := into
+	if baseBranch == "" {
+		baseBranch = cfg.BaseBranch
+	}
+	if baseBranch == "" {
+		baseBranch = git.GetDefaultBranch(cfg.Remote)
+	}
+	if baseBranch == branch {
+		return fmt.Errorf("Cannot finish '%s' into itself. Use --into <branch>.", branch)
+	}
+	if !git.RefExists("refs/heads/" + baseBranch) {
+		return fmt.Errorf("Base branch '%s' does not exist locally.", baseBranch)
+	}
+
+	// Step 4: Verify the worktree is clean
+	dirty, err := git.StatusLines(worktreePath)
+	if err != nil {
+		return err
+	}
+	if len(dirty) > 0 {
+		return fmt.Errorf("Worktree has uncommitted changes. Commit or stash them first:\n%s", formatDirtyLines(dirty))
+	}
+
+	commits, err := git.CountCommits(baseBranch, branch)
+	if err != nil {
+		return err
+	}
+
+	// Step 5: Show summary and confirm
+	fmt.Println()
+	fmt.Printf("  Branch:    %s\n", ui.BoldText(branch))
+	fmt.Printf("  Into:      %s %s\n", ui.BoldText(baseBranch), ui.DimText("("+strategy+")"))
+	fmt.Printf("  Commits:   %d\n", commits)
+	fmt.Printf("  Path:      %s\n", ui.DimText(worktreePath))
+	fmt.Println()
+
+	if !force {
+		ui.Warn(fmt.Sprintf("This will %s %s into %s, then remove the worktree and delete the branch.", strategy, branch, baseBranch))
+		if !promptDeleteConfirm() {
+			ui.Info("Cancelled.")
+			return nil
+		}
+	}
+
+	// Step 6: Integrate the branch into the base branch
+	if commits == 0 {
+		ui.Info(fmt.Sprintf("%s has no commits that aren't in %s; nothing to integrate", branch, baseBranch))
+	} else if err := integrateBranch(branch, worktreePath, baseBranch, strategy); err != nil {
+		return fmt.Errorf("%w\nThe worktree and branch were left in place.", err)
+	}
+
+	// Step 7: Remove worktree, session and branch (the delete flow)
+	hookContext := hooks.Context{
+		RepoName:     repoName,
+		RepoRoot:     mainCheckout,
+		Branch:       branch,
+		BaseBranch:   baseBranch,
+		WorktreePath: worktreePath,
+		SessionName:  git.GenerateSessionName(repoName, branch),
+	}
+	if err := removeManagedWorktree(cfg, hookContext); err != nil {
+		return err
+	}
+
+	// Step 8: Success message
+	fmt.Println()
+	ui.Success(fmt.Sprintf("Finished %s into %s", ui.CyanText(branch), ui.CyanText(baseBranch)))
+
+	return nil
+}
+
+// integrateBranch integrates branch into baseBranch using strategy.
+// If baseBranch is checked out in a clean checkout, the integration happens
+// there. Otherwise it happens in a temporary detached worktree, and the result
+// is fast-forwarded into the checkout that has baseBranch (which keeps any
+// non-conflicting local changes), or written directly to the branch ref.
+func integrateBranch(branch, branchPath, baseBranch, strategy string) error {
+	baseCheckout, checkedOut := git.FindBranchWorktree(baseBranch)
+	if checkedOut {
+		dirty, err := git.StatusLines(baseCheckout)
+		if err != nil {
+			return err
+		}
+		if len(dirty) == 0 {
+			ui.Info(fmt.Sprintf("Integrating %s into %s in %s...", branch, baseBranch, baseCheckout))
+			if err := worktree.Integrate(baseCheckout, branch, branchPath, strategy); err != nil {
+				return err
+			}
+			ui.Success(fmt.Sprintf("Integrated into %s", baseBranch))
+			return nil
+		}
+		ui.Info(fmt.Sprintf("%s has uncommitted changes; integrating in a temporary worktree", baseCheckout))
+	}
+
+	oldCommit, err := git.ResolveCommit(branchPath, "refs/heads/"+baseBranch)
+	if err != nil {
+		return err
+	}
+	tempPath, err := worktree.AddTemporary(oldCommit)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := worktree.Remove(tempPath); err != nil {
+			ui.Warn(fmt.Sprintf("Could not remove temporary worktree %s: %v", tempPath, err))
+		}
+	}()
+
+	ui.Info(fmt.Sprintf("Integrating %s into %s...", branch, baseBranch))
+	if err := worktree.Integrate(tempPath, branch, branchPath, strategy); err != nil {
+		return err
+	}
+	newCommit, err := git.ResolveCommit(tempPath, "HEAD")
+	if err != nil {
+		return err
+	}
+
+	if checkedOut {
+		if err := worktree.FastForward(baseCheckout, newCommit); err != nil {
+			return fmt.Errorf("could not fast-forward %s in %s (local changes conflict?): %w\nIntegrated commit: %s", baseBranch, baseCheckout, err, newCommit)
+		}
+	} else if err := git.UpdateBranch(baseBranch, newCommit, oldCommit); err != nil {
+		return err
+	}
+	ui.Success(fmt.Sprintf("Integrated into %s", baseBranch))
+	return nil
+}
+
+// formatDirtyLines indents git status lines for an error message, truncating long lists.
+func formatDirtyLines(lines []string) string {
+	shown := lines
+	if len(shown) > maxDirtyLines {
+		shown = shown[:maxDirtyLines]
+	}
+	var sb strings.Builder
+	for _, line := range shown {
+		sb.WriteString("  " + line + "\n")
+	}
+	if len(lines) > maxDirtyLines {
+		sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(lines)-maxDirtyLines))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/ui"
+)
+
+// globalWorktree is a worktree found by scanning $WORKTREE_DIR.
+type globalWorktree struct {
+	repoName string // Session name prefix: base name of the main checkout
+	mainRepo string // Main checkout, or "" if the worktree's .git link is broken
+	path     string
+	branch   string // Empty for a detached HEAD
+}
+
+// scanGlobalWorktrees finds every worktree under $WORKTREE_DIR/<repo>/ for all
+// repositories, resolving each back to its main checkout through the
+// worktree's .git file. Results are sorted by repository, then path, so
+// worktrees of the same main checkout are adjacent.
+func scanGlobalWorktrees(worktreeDir string) ([]globalWorktree, error) {
+	repoDirs, err := os.ReadDir(worktreeDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", worktreeDir, err)
+	}
+
+	var worktrees []globalWorktree
+	for _, repoDir := range repoDirs {
+		if !repoDir.IsDir() {
+			continue
+		}
+		dirPath := filepath.Join(worktreeDir, repoDir.Name())
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dirPath, entry.Name())
+			if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+				continue // Not a worktree
+			}
+			wt := globalWorktree{repoName: repoDir.Name(), path: path}
+			if mainRepo, err := git.ResolveMainRepo(path); err == nil {
+				wt.mainRepo = mainRepo
+				wt.repoName = filepath.Base(mainRepo)
+				wt.branch = git.GetCurrentBranch(path)
+			}
+			worktrees = append(worktrees, wt)
+		}
+	}
+
+	sort.SliceStable(worktrees, func(i, j int) bool {
+		if worktrees[i].repoName != worktrees[j].repoName {
+			return worktrees[i].repoName < worktrees[j].repoName
+		}
+		if worktrees[i].mainRepo != worktrees[j].mainRepo {
+			return worktrees[i].mainRepo < worktrees[j].mainRepo
+		}
+		return worktrees[i].path < worktrees[j].path
+	})
+	return worktrees, nil
+}
+
+// listGlobal prints the worktrees of every repository under worktreeDir,
+// grouped by main checkout. Worktrees whose repository can't be found are
+// listed last so they can be cleaned up.
+func listGlobal(worktreeDir string) error {
+	worktrees, err := scanGlobalWorktrees(worktreeDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%sWorktrees in %s\n", ui.Bold, ui.CyanText(worktreeDir))
+	fmt.Println("════════════════════════════════════════════════════════════════")
+
+	if len(worktrees) == 0 {
+		ui.Info(fmt.Sprintf("No worktrees found. Use %s to create one.", ui.BoldText("mxt new [branch]")))
+		return nil
+	}
+
+	var orphaned []globalWorktree
+	currentRepo := ""
+	for _, wt := range worktrees {
+		if wt.mainRepo == "" {
+			orphaned = append(orphaned, wt)
+			continue
+		}
+		if wt.mainRepo != currentRepo {
+			currentRepo = wt.mainRepo
+			fmt.Println()
+			fmt.Printf("%s  %s\n", ui.BoldText(wt.repoName), ui.DimText(wt.mainRepo))
+		}
+		fmt.Println()
+		info := createWorktreeInfo(wt.path, wt.branch, wt.repoName)
+		if wt.branch == "" {
+			info.Detached = true
+			info.Head, _ = git.ResolveCommit(wt.path, "HEAD")
+		}
+		displayWorktree(info)
+	}
+
+	if len(orphaned) > 0 {
+		fmt.Println()
+		ui.Warn("Repository not found (broken .git link) for:")
+		for _, wt := range orphaned {
+			fmt.Printf("  %s\n", ui.DimText(wt.path))
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
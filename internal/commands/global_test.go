@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+func TestScanGlobalWorktrees(t *testing.T) {
+	base := t.TempDir()
+	worktreeDir := filepath.Join(base, "wt")
+	repo := filepath.Join(base, "src", "app")
+	repoGit := testutil.NewRepo(t, repo)
+	repoGit("worktree", "add", "-q", "-b", "feature/auth", filepath.Join(worktreeDir, "app", "feature-auth"))
+	repoGit("worktree", "add", "-q", "--detach", filepath.Join(worktreeDir, "app", "scratch"))
+
+	// A worktree whose repository was deleted, and a directory that isn't a worktree
+	broken := filepath.Join(worktreeDir, "gone", "old-branch")
+	if err := os.MkdirAll(broken, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(broken, ".git"), []byte("gitdir: /nonexistent/.git/worktrees/old-branch\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(worktreeDir, "app", "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees, err := scanGlobalWorktrees(worktreeDir)
+	if err != nil {
+		t.Fatalf("scanGlobalWorktrees() error = %v", err)
+	}
+	if len(worktrees) != 3 {
+		t.Fatalf("scanGlobalWorktrees() returned %d worktrees, want 3: %#v", len(worktrees), worktrees)
+	}
+
+	expected := []struct {
+		repoName string
+		base     string
+		branch   string
+		resolved bool
+	}{
+		{"app", "feature-auth", "feature/auth", true},
+		{"app", "scratch", "", true},
+		{"gone", "old-branch", "", false},
+	}
+	for i, want := range expected {
+		got := worktrees[i]
+		if got.repoName != want.repoName || filepath.Base(got.path) != want.base || got.branch != want.branch || (got.mainRepo != "") != want.resolved {
+			t.Errorf("worktrees[%d] = %#v, want repo %q, dir %q, branch %q, resolved %v", i, got, want.repoName, want.base, want.branch, want.resolved)
+		}
+	}
+	if got := worktrees[0].mainRepo; !samePath(got, repo) {
+		t.Errorf("mainRepo = %q, want %q", got, repo)
+	}
+
+	if worktrees, err := scanGlobalWorktrees(filepath.Join(base, "missing")); err != nil || len(worktrees) != 0 {
+		t.Errorf("scanGlobalWorktrees(missing) = %v, %v; want no worktrees", worktrees, err)
+	}
+}
+
+func TestParseJumpSession(t *testing.T) {
+	item := formatJumpItem("app_feature-auth", globalWorktree{repoName: "app", branch: "feature/auth", path: "/wt/app/feature-auth"})
+	if got := parseJumpSession(item + "\n"); got != "app_feature-auth" {
+		t.Errorf("parseJumpSession(%q) = %q, want %q", item, got, "app_feature-auth")
+	}
+}
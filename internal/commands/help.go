@@ -21,7 +21,18 @@ func HelpCommand(version string) {
 	fmt.Println("        --local                       Create project config (.mxt.toml in repo root)")
 	fmt.Println("        --import                      Import legacy key=value config to TOML")
 	fmt.Println("        --reinit                      Overwrite existing config without prompting")
-	fmt.Printf("    %sconfig%s                            Show current config (global + project)\n", ui.Cyan, ui.Reset)
+	fmt.Println("        -y, --yes                     Accept every default (with --local, what init detects)")
+	fmt.Println("        --worktree-dir, --terminal, --sandbox-tool, --copy-files, --pre-session-cmd, --tmux-layout")
+	fmt.Println("                                      Answer a prompt; giving any skips the prompts (for scripts)")
+	fmt.Printf("    %sconfig%s                            Show current config (global + project + includes)\n", ui.Cyan, ui.Reset)
+	fmt.Println("        --effective                   Show the merged config and where each value comes from")
+	fmt.Println("        --branch <name>               With --effective, apply the [[rules]] matching this branch")
+	fmt.Println("        --profile <name>              With --effective, apply this profile")
+	fmt.Println("        get <key>                     Print a key from the global config file")
+	fmt.Println("        set <key> <value>             Set a key, keeping comments (lists: '[\"a\", \"b\"]' or a,b)")
+	fmt.Println("        unset <key>                   Remove a key from the global config file")
+	fmt.Println("        edit                          Open the config file in $VISUAL or $EDITOR")
+	fmt.Println("        --local                       Use the project config (.mxt.toml) instead")
 	fmt.Println()
 	fmt.Println("        Terminal options: terminal, iterm2, ghostty, current")
 	fmt.Println("        - terminal: macOS Terminal.app (new window)")
@@ -31,47 +42,105 @@ func HelpCommand(version string) {
 	fmt.Println()
 	fmt.Printf("    %snew%s [branch] [options]             Create worktree + tmux session\n", ui.Cyan, ui.Reset)
 	fmt.Println("        (prompts for branch when omitted)")
-	fmt.Println("        --from <branch>               Base branch (default: main/master)")
+	fmt.Println("        --from <branch>               Base branch (default: base_branch, or <remote>/HEAD)")
 	fmt.Println("        --run <claude|codex>          Auto-run command in agent window")
+	fmt.Println("        --sparse <dir,dir>            Sparse checkout of these directories (default: sparse_paths)")
+	fmt.Println("        --profile <name>              Apply [profiles.<name>] from config (default: default_profile)")
 	fmt.Println("        --bg                          Create session without opening terminal")
+	fmt.Println("        --dry-run                     Show worktree path and files to copy, change nothing")
 	fmt.Println()
-	fmt.Printf("    %slist%s                              List worktrees, diff stats, session status\n", ui.Cyan, ui.Reset)
+	fmt.Printf("    %slist%s [options]                     List worktrees, diff stats, session status\n", ui.Cyan, ui.Reset)
+	fmt.Println("        --all-worktrees               Also show the main checkout and unmanaged worktrees")
+	fmt.Println("        --global, -g                  Worktrees of every repo under worktree_dir (from anywhere)")
 	fmt.Println()
 	fmt.Printf("    %sdelete%s <branch> [--force]          Delete worktree and branch (with confirmation)\n", ui.Cyan, ui.Reset)
+	fmt.Println("        (delete and sessions also accept a worktree path, e.g. for detached worktrees)")
+	fmt.Println()
+	fmt.Printf("    %srename%s <old> <new>                 Rename branch, move worktree, rename session\n", ui.Cyan, ui.Reset)
+	fmt.Println("        (rolls back completed steps if one fails)")
+	fmt.Println()
+	fmt.Printf("    %sadopt%s <path|branch>                Bring an existing worktree or branch under mxt\n", ui.Cyan, ui.Reset)
+	fmt.Println("        (moves the worktree to its mxt path, or creates one for the branch)")
+	fmt.Println()
+	fmt.Printf("    %slock%s <branch|path> [--reason txt]  Lock worktree against delete, finish and prune\n", ui.Cyan, ui.Reset)
+	fmt.Printf("    %sunlock%s <branch|path>               Remove the lock\n", ui.Cyan, ui.Reset)
+	fmt.Println()
+	fmt.Printf("    %smigrate-dir%s [options]              Move worktrees into the current worktree_dir\n", ui.Cyan, ui.Reset)
+	fmt.Println("        --from <dir>                  Previous worktree_dir (default: detect)")
+	fmt.Println("        --dry-run                     Show the moves, change nothing")
+	fmt.Println()
+	fmt.Printf("    %sdiff%s <branch> [options]            Diff worktree against its merge-base with base branch\n", ui.Cyan, ui.Reset)
+	fmt.Println("        (includes uncommitted and untracked changes)")
+	fmt.Println("        --stat | --name-only          Summary or file names only")
+	fmt.Println("        --tool <difftool>             Open changes with git difftool")
+	fmt.Println()
+	fmt.Printf("    %sfinish%s <branch> [options]          Integrate branch into base, then delete worktree\n", ui.Cyan, ui.Reset)
+	fmt.Println("        --merge | --squash | --rebase Integration strategy (default: --merge)")
+	fmt.Println("        --into <branch>               Base branch (default: base_branch, or <remote>/HEAD)")
+	fmt.Println("        --force, -f                   Skip confirmation prompt")
 	fmt.Println()
 	fmt.Printf("    %ssessions%s <action> <branch> [opts]  Manage tmux session for a worktree\n", ui.Cyan, ui.Reset)
 	fmt.Println("        open   <branch> [--run cmd]   Create session & open terminal")
 	fmt.Println("        close  <branch>               Kill tmux session")
 	fmt.Println("        relaunch <branch> [--run cmd] Close + reopen session")
+	fmt.Println("        --profile <name>              Config profile for open/relaunch (default: the worktree's)")
 	fmt.Println("        attach <branch> [dev|agent]   Attach to session (optionally select window)")
 	fmt.Println("        (omit branch to select interactively when running in a TTY)")
 	fmt.Println()
+	fmt.Printf("    %sjump%s                              Pick any running mxt session (fzf) and attach\n", ui.Cyan, ui.Reset)
+	fmt.Println("        (switches client when run inside tmux; works from any directory)")
+	fmt.Println()
+	fmt.Printf("    %sdoctor%s                            Check tools, config and worktrees (exits 1 on problems)\n", ui.Cyan, ui.Reset)
+	fmt.Println()
+	fmt.Printf("    %strust%s [--revoke]                  Allow the commands in this repo's .mxt.toml to run\n", ui.Cyan, ui.Reset)
+	fmt.Println("        (needed again whenever the file changes)")
+	fmt.Println()
 	fmt.Printf("    %shelp%s                              Show this help message\n", ui.Cyan, ui.Reset)
 	fmt.Println()
 	fmt.Printf("%sEXAMPLES%s\n", ui.Bold, ui.Reset)
 	fmt.Println("    mxt init                          # Global setup")
 	fmt.Println("    mxt init --local                  # Project-specific copy_files")
 	fmt.Println("    mxt init --reinit                 # Overwrite existing config")
+	fmt.Println("    mxt init --terminal current       # Non-interactive setup, defaults for the rest")
+	fmt.Println("    mxt init --local --yes            # Accept the detected project defaults")
 	fmt.Println("    mxt new                          # Prompt for branch name")
 	fmt.Println("    mxt new feature-auth              # New worktree from main")
 	fmt.Println("    mxt new fix-bug --from develop    # New worktree from develop")
 	fmt.Println("    mxt new feature-ai --run claude   # Auto-launch claude code")
 	fmt.Println("    mxt new fix-bug --bg              # Create without opening terminals")
+	fmt.Println("    mxt new fix-bug --dry-run         # Preview which files would be copied")
+	fmt.Println("    mxt new api-fix --sparse packages/api  # Check out only packages/api")
 	fmt.Println("    mxt list                          # Show all worktrees + status")
+	fmt.Println("    mxt list --global                 # Worktrees of all repos")
+	fmt.Println("    mxt jump                          # Jump to any session on this machine")
 	fmt.Println("    mxt sessions close feature-auth   # Kill tmux sessions")
 	fmt.Println("    mxt sessions relaunch fix-bug     # Restart sessions")
 	fmt.Println("    mxt delete feature-auth           # Remove worktree + branch")
+	fmt.Println("    mxt rename fix-bug fix-login      # Rename branch, worktree and session")
+	fmt.Println("    mxt lock release-2.x              # Protect a long-lived worktree")
+	fmt.Println("    mxt adopt ../myrepo-hotfix        # Move a hand-made worktree into mxt")
+	fmt.Println("    mxt diff fix-login --stat         # Review what the agent changed")
+	fmt.Println("    mxt finish fix-login --squash     # Squash into main, then clean up")
 	fmt.Println()
 	fmt.Printf("%sCONFIG%s\n", ui.Bold, ui.Reset)
 	fmt.Println("    Global:  ~/.config/mxt/config.toml (TOML)")
-	fmt.Println("             (worktree_dir, terminal, sandbox_tool, copy_files, pre_session_cmd, tmux_layout)")
+	fmt.Println("             (worktree_dir, terminal, sandbox_tool, copy_files, copy_ignored, pre_session_cmd, tmux_layout)")
+	fmt.Println("             (fetch_before_new, remote, base_branch: branch from <remote>/<base> after fetching)")
+	fmt.Println("             (sparse_paths: cone-mode sparse checkout directories for new worktrees)")
+	fmt.Println("             (init_submodules, lfs_pull: set up submodules and Git LFS in new worktrees)")
 	fmt.Println("    Project: .mxt.toml in repo root (TOML overrides global settings)")
 	fmt.Println("    Legacy:  mxt init --import      (convert key=value configs)")
 	fmt.Println("    Env:     MXT_CONFIG_DIR=/path    (override global config dir)")
+	fmt.Println("             MXT_<KEY>=value         (override a key, e.g. MXT_TERMINAL, MXT_HOOKS_PRE_DELETE)")
+	fmt.Println("    Flag:    mxt -c key=value ...    (override a key for one run; --set, repeatable)")
 	fmt.Println()
 	fmt.Printf("    %sHooks & Layout:%s\n", ui.Bold, ui.Reset)
 	fmt.Println("    - pre_session_cmd:  Runs after worktree setup, before tmux session")
 	fmt.Println("                        Good for: bundle install, npm install, db:migrate")
+	fmt.Println("    - pre_session_mode: block (default) | window (setup tmux window) | agent")
+	fmt.Println("                        pre_session_timeout = \"10m\" kills a slow command; see mxt list")
+	fmt.Println("    - [hooks]:          post_create, pre_delete, post_delete, on_session_open")
+	fmt.Println("                        Run via sh -c with MXT_HOOK_* env vars; on_failure = abort|warn|prompt")
 	fmt.Println("    - sandbox_tool:     Optional command prefix to run tmux in a sandbox")
 	fmt.Println("                        Example: firejail --private, docker run --rm -it ...")
 	fmt.Println()
@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/hooks"
+)
+
+var runHookCommand = hooks.Run
+
+// runHook runs the configured hook for stage in dir, if any, and applies its
+// failure policy. It returns an error only when the command should abort.
+func runHook(cfg *config.Config, stage, dir string, ctx hooks.Context) error {
+	hook, ok := cfg.Hooks[stage]
+	if !ok || hook.Command == "" {
+		return nil
+	}
+
+	err := runHookCommand(stage, hook.Command, dir, ctx, cfg.SandboxTool)
+
+	var confirm func() bool
+	if isInteractive() {
+		confirm = promptContinue
+	}
+	return hooks.HandleFailure(stage, hook.OnFailure, err, confirm)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
 	"github.com/gkarolyi/mxt/internal/ui"
 	"github.com/pelletier/go-toml/v2"
 	"golang.org/x/term"
@@ -24,16 +25,124 @@ const logo = `███╗   ███╗██╗  ██╗██████
   Tmux Worktree Session Manager v1.1.0
 `
 
+// InitAnswers holds init's answers given as flags; nil means not given.
+// When any is given, or Defaults is set, init asks nothing and uses the
+// defaults (or, with --local, what it detects in the repo) for the rest.
+type InitAnswers struct {
+	WorktreeDir   *string
+	Terminal      *string
+	SandboxTool   *string
+	CopyFiles     *string
+	PreSessionCmd *string
+	TmuxLayout    *string
+	Defaults      bool // --yes: accept every default without asking
+}
+
+func (a InitAnswers) given() bool {
+	if a.Defaults {
+		return true
+	}
+	for _, answer := range []*string{a.WorktreeDir, a.Terminal, a.SandboxTool, a.CopyFiles, a.PreSessionCmd, a.TmuxLayout} {
+		if answer != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // InitCommand implements the init command
-func InitCommand(local bool, reinit bool, importLegacy bool) error {
+func InitCommand(local bool, reinit bool, importLegacy bool, answers InitAnswers) error {
+	if local && (answers.WorktreeDir != nil || answers.Terminal != nil) {
+		return fmt.Errorf("--worktree-dir and --terminal only apply to the global config")
+	}
+
 	// Display logo
 	fmt.Print(logo)
 	fmt.Println()
 
 	if local {
-		return initProjectConfig(reinit, importLegacy)
+		return initProjectConfig(reinit, importLegacy, answers)
+	}
+	return initGlobalConfig(reinit, importLegacy, answers)
+}
+
+// initPrompter asks init's questions on stdin, unless answers were given
+// as flags.
+type initPrompter struct {
+	reader *bufio.Reader
+	skip   bool // Answers came from flags or --yes: use defaults instead of asking
+}
+
+func newInitPrompter(answers InitAnswers) *initPrompter {
+	return &initPrompter{reader: bufio.NewReader(os.Stdin), skip: answers.given()}
+}
+
+// ask returns answer when it was given as a flag. Otherwise it prints help
+// and label and reads a line, returning def for an empty line and "" for
+// "none".
+func (p *initPrompter) ask(answer *string, label, def string, help ...string) string {
+	if answer != nil {
+		return *answer
+	}
+	if p.skip {
+		return def
+	}
+	if len(help) > 0 {
+		fmt.Println()
+		for _, line := range help {
+			ui.Info(line)
+		}
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		display := def
+		if display == "" {
+			display = "none"
+		}
+		fmt.Printf("%s [%s]: ", label, display)
+	}
+	input, _ := p.reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	switch input {
+	case "":
+		return def
+	case "none":
+		return ""
+	default:
+		return input
 	}
-	return initGlobalConfig(reinit, importLegacy)
+}
+
+// confirmOverwrite shows the config that exists at path and asks whether to
+// replace it. Without a prompt (answers given as flags, or --yes), only
+// --reinit replaces it.
+func (p *initPrompter) confirmOverwrite(path, label string, reinit bool) (bool, error) {
+	if p.skip && !reinit {
+		return false, fmt.Errorf("%s already exists at %s (use --reinit to overwrite)", label, path)
+	}
+	ui.Warn(fmt.Sprintf("%s already exists at %s", label, path))
+	fmt.Println()
+	content, _ := os.ReadFile(path)
+	fmt.Println(string(content))
+	fmt.Println()
+	shouldOverwrite, err := shouldOverwriteConfig(p.reader, os.Stdout, term.IsTerminal(int(os.Stdin.Fd())), reinit)
+	if err != nil || !shouldOverwrite {
+		return false, err
+	}
+	fmt.Println()
+	return true, nil
+}
+
+// validateInitConfig checks generated config content the way loading it
+// would, so bad flag values are reported before anything is written.
+func validateInitConfig(content string) error {
+	file, err := config.ParseConfig(strings.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if err := config.ValidateConfig(file); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
 }
 
 func shouldOverwriteConfig(reader *bufio.Reader, writer io.Writer, isTerminal bool, reinit bool) (bool, error) {
@@ -73,7 +182,11 @@ func importLegacyConfig(legacyPath string, targetPath string, reinit bool) (stri
 	if err != nil {
 		return "", fmt.Errorf("failed to parse legacy config at %s: %w", legacyPath, err)
 	}
-	encoded, err := config.EncodeConfig(parsed)
+	converted, err := config.ConvertLegacyConfig(parsed)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert legacy config at %s: %w", legacyPath, err)
+	}
+	encoded, err := config.EncodeConfig(converted)
 	if err != nil {
 		return "", fmt.Errorf("failed to encode TOML config: %w", err)
 	}
@@ -86,7 +199,7 @@ func importLegacyConfig(legacyPath string, targetPath string, reinit bool) (stri
 	return encoded, nil
 }
 
-func initGlobalConfig(reinit bool, importLegacy bool) error {
+func initGlobalConfig(reinit bool, importLegacy bool, answers InitAnswers) error {
 	configPath := config.GetGlobalConfigPath()
 	if importLegacy {
 		content, err := importLegacyConfig(config.GetLegacyGlobalConfigPath(), configPath, reinit)
@@ -99,23 +212,14 @@ func initGlobalConfig(reinit bool, importLegacy bool) error {
 		return nil
 	}
 	configDir := filepath.Dir(configPath)
-	reader := bufio.NewReader(os.Stdin)
+	prompter := newInitPrompter(answers)
 
 	// Check if config already exists
 	if _, err := os.Stat(configPath); err == nil {
-		ui.Warn(fmt.Sprintf("Config already exists at %s", configPath))
-		fmt.Println()
-		content, _ := os.ReadFile(configPath)
-		fmt.Println(string(content))
-		fmt.Println()
-		shouldOverwrite, err := shouldOverwriteConfig(reader, os.Stdout, term.IsTerminal(int(os.Stdin.Fd())), reinit)
-		if err != nil {
+		shouldOverwrite, err := prompter.confirmOverwrite(configPath, "Config", reinit)
+		if err != nil || !shouldOverwrite {
 			return err
 		}
-		if !shouldOverwrite {
-			return nil
-		}
-		fmt.Println()
 	}
 
 	// Create config directory if it doesn't exist
@@ -128,83 +232,34 @@ func initGlobalConfig(reinit bool, importLegacy bool) error {
 		return fmt.Errorf("failed to load defaults: %w", err)
 	}
 
-	worktreeDir := defaults["worktree_dir"]
-	terminal := defaults["terminal"]
-	sandboxTool := defaults["sandbox_tool"]
-
 	fmt.Println()
 
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Printf("Worktree base directory [%s]: ", worktreeDir)
-	}
-	inputDir, _ := reader.ReadString('\n')
-	inputDir = strings.TrimSpace(inputDir)
-	if inputDir != "" {
-		worktreeDir = inputDir
+	worktreeDir := prompter.ask(answers.WorktreeDir, "Worktree base directory", *defaults.WorktreeDir)
+	terminal := prompter.ask(answers.Terminal, "Terminal app (terminal/iterm2/ghostty/current)", *defaults.Terminal)
+	if _, err := terminalRequirement(terminal); err != nil {
+		return err
 	}
+	sandboxTool := prompter.ask(answers.SandboxTool, "Sandbox tool (firejail/docker, optional)", *defaults.SandboxTool)
 
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Printf("Terminal app (terminal/iterm2/ghostty/current) [%s]: ", terminal)
-	}
-	inputTerm, _ := reader.ReadString('\n')
-	inputTerm = strings.TrimSpace(inputTerm)
-	if inputTerm != "" {
-		terminal = inputTerm
-	}
-	displaySandbox := sandboxTool
-	if displaySandbox == "" {
-		displaySandbox = "none"
-	}
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Printf("Sandbox tool (firejail/docker, optional) [%s]: ", displaySandbox)
-	}
-	inputSandbox, _ := reader.ReadString('\n')
-	inputSandbox = strings.TrimSpace(inputSandbox)
-	if inputSandbox != "" {
-		if inputSandbox == "none" {
-			sandboxTool = ""
-		} else {
-			sandboxTool = inputSandbox
-		}
-	}
-
-	fmt.Println()
-
-	// Copy files
-	ui.Info("Enter files to copy into new worktrees (relative to repo root).")
-	ui.Info("Comma-separated, e.g.: .env,.env.local,CLAUDE.md")
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Files to copy: ")
-	}
-	copyFiles, _ := reader.ReadString('\n')
-	copyFiles = strings.TrimSpace(copyFiles)
-
-	// Pre-session command
-	fmt.Println()
-	ui.Info("Optional: Command to run after worktree setup, before tmux session.")
-	ui.Info("Runs in worktree dir. Good for: bundle install, npm install, db:migrate")
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Pre-session command: ")
-	}
-	preSessionCmd, _ := reader.ReadString('\n')
-	preSessionCmd = strings.TrimSpace(preSessionCmd)
-
-	// Tmux layout
-	fmt.Println()
-	ui.Info("Optional: Tmux layout - define windows and panes.")
-	ui.Info("Enter a single line now, or edit the config file to use a TOML multi-line string.")
-	ui.Info("Example (single line): dev:hx|lazygit,server:bin/server,agent:")
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Tmux layout: ")
-	}
-	tmuxLayout, _ := reader.ReadString('\n')
-	tmuxLayout = strings.TrimSpace(tmuxLayout)
+	copyFiles := prompter.ask(answers.CopyFiles, "Files to copy", "",
+		"Enter files to copy into new worktrees (relative to repo root).",
+		"Comma-separated, e.g.: .env,.env.local,CLAUDE.md")
+	preSessionCmd := prompter.ask(answers.PreSessionCmd, "Pre-session command", "",
+		"Optional: Command to run after worktree setup, before tmux session.",
+		"Runs in worktree dir. Good for: bundle install, npm install, db:migrate")
+	tmuxLayout := prompter.ask(answers.TmuxLayout, "Tmux layout", "",
+		"Optional: Tmux layout - define windows and panes.",
+		"Enter a single line now, or edit the config file to use a TOML multi-line string.",
+		"Example (single line): dev:hx|lazygit,server:bin/server,agent:")
 
 	// Generate config file content
 	content, err := generateGlobalConfigContent(worktreeDir, terminal, sandboxTool, copyFiles, preSessionCmd, tmuxLayout)
 	if err != nil {
 		return fmt.Errorf("failed to format config: %w", err)
 	}
+	if err := validateInitConfig(content); err != nil {
+		return err
+	}
 
 	// Write config file
 	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
@@ -219,7 +274,7 @@ func initGlobalConfig(reinit bool, importLegacy bool) error {
 	return nil
 }
 
-func initProjectConfig(reinit bool, importLegacy bool) error {
+func initProjectConfig(reinit bool, importLegacy bool, answers InitAnswers) error {
 	gitRoot, err := config.FindGitRoot(".")
 	if err != nil {
 		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
@@ -236,77 +291,58 @@ func initProjectConfig(reinit bool, importLegacy bool) error {
 		fmt.Print(content)
 		return nil
 	}
-	reader := bufio.NewReader(os.Stdin)
+	prompter := newInitPrompter(answers)
 
 	// Check if config already exists
 	if _, err := os.Stat(configPath); err == nil {
-		ui.Warn(fmt.Sprintf("Project config already exists at %s", configPath))
-		fmt.Println()
-		content, _ := os.ReadFile(configPath)
-		fmt.Println(string(content))
-		fmt.Println()
-		shouldOverwrite, err := shouldOverwriteConfig(reader, os.Stdout, term.IsTerminal(int(os.Stdin.Fd())), reinit)
-		if err != nil {
+		shouldOverwrite, err := prompter.confirmOverwrite(configPath, "Project config", reinit)
+		if err != nil || !shouldOverwrite {
 			return err
 		}
-		if !shouldOverwrite {
-			return nil
-		}
-		fmt.Println()
-	}
-
-	fmt.Println()
-
-	// Copy files
-	ui.Info("Enter files to copy into new worktrees for this project (relative to repo root).")
-	ui.Info("Comma-separated, e.g.: .env,.env.local,CLAUDE.md")
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Files to copy: ")
 	}
-	copyFiles, _ := reader.ReadString('\n')
-	copyFiles = strings.TrimSpace(copyFiles)
 
-	// Pre-session command
 	fmt.Println()
-	ui.Info("Optional: Command to run after worktree setup, before tmux session.")
-	ui.Info("Runs in worktree dir. Good for: bundle install, npm install, db:migrate")
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Pre-session command: ")
-	}
-	preSessionCmd, _ := reader.ReadString('\n')
-	preSessionCmd = strings.TrimSpace(preSessionCmd)
-	// Sandbox tool
-	fmt.Println()
-	ui.Info("Optional: Sandbox tool command prefix for tmux sessions.")
-	ui.Info("Example: firejail --private, docker run --rm -it ...")
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Sandbox tool: ")
-	}
-	sandboxTool, _ := reader.ReadString('\n')
-	sandboxTool = strings.TrimSpace(sandboxTool)
 
-	// Tmux layout
-	fmt.Println()
-	ui.Info("Optional: Tmux layout - define windows and panes.")
-	ui.Info("Enter a single line now, or edit the config file to use a TOML multi-line string.")
-	ui.Info("Example (single line): dev:hx|lazygit,server:bin/server,agent:")
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Tmux layout: ")
-	}
-	tmuxLayout, _ := reader.ReadString('\n')
-	tmuxLayout = strings.TrimSpace(tmuxLayout)
+	// Propose copy_files and pre_session_cmd from the project's files
+	ignored, _ := git.ListIgnoredFiles(gitRoot)
+	detected := detectProject(gitRoot, ignored)
+	if len(detected.types) > 0 {
+		ui.Info(fmt.Sprintf("Detected %s", strings.Join(detected.types, ", ")))
+	}
+
+	copyFiles := prompter.ask(answers.CopyFiles, "Files to copy", strings.Join(detected.copyFiles, ","),
+		"Enter files to copy into new worktrees for this project (relative to repo root).",
+		"Comma-separated, e.g.: .env,.env.local,CLAUDE.md")
+	preSessionCmd := prompter.ask(answers.PreSessionCmd, "Pre-session command", detected.preSessionCmd,
+		"Optional: Command to run after worktree setup, before tmux session.",
+		"Runs in worktree dir. Good for: bundle install, npm install, db:migrate")
+	sandboxTool := prompter.ask(answers.SandboxTool, "Sandbox tool", "",
+		"Optional: Sandbox tool command prefix for tmux sessions.",
+		"Example: firejail --private, docker run --rm -it ...")
+	tmuxLayout := prompter.ask(answers.TmuxLayout, "Tmux layout", "",
+		"Optional: Tmux layout - define windows and panes.",
+		"Enter a single line now, or edit the config file to use a TOML multi-line string.",
+		"Example (single line): dev:hx|lazygit,server:bin/server,agent:")
 
 	// Generate config file content
 	content, err := generateProjectConfigContent(copyFiles, sandboxTool, preSessionCmd, tmuxLayout)
 	if err != nil {
 		return fmt.Errorf("failed to format config: %w", err)
 	}
+	if err := validateInitConfig(content); err != nil {
+		return err
+	}
 
 	// Write config file
 	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	// The commands were just entered here, so trust them
+	if err := config.Trust(configPath); err != nil {
+		ui.Warn(fmt.Sprintf("Could not trust %s: %v", configPath, err))
+	}
+
 	// Display success message
 	ui.Success(fmt.Sprintf("Project config written to %s", configPath))
 	fmt.Println()
@@ -346,7 +382,7 @@ func generateGlobalConfigContent(worktreeDir, terminal, sandboxTool, copyFiles,
 	var sb strings.Builder
 	sb.WriteString("# mxt configuration (TOML)\n")
 	sb.WriteString(fmt.Sprintf("# Generated on %s\n\n", timestamp))
-	sb.WriteString("# Base directory for worktrees\n")
+	sb.WriteString("# Base directory for worktrees (run mxt migrate-dir in each repo after changing it)\n")
 	sb.WriteString(fmt.Sprintf("worktree_dir = %s\n\n", worktreeValue))
 
 	sb.WriteString("# Terminal app: terminal | iterm2 | ghostty | current\n")
@@ -355,14 +391,35 @@ func generateGlobalConfigContent(worktreeDir, terminal, sandboxTool, copyFiles,
 	sb.WriteString("# Example: firejail --private, docker run --rm -it ...\n")
 	sb.WriteString(fmt.Sprintf("sandbox_tool = %s\n\n", sandboxValue))
 
-	sb.WriteString("# Files to copy from repo root into new worktrees (comma-separated, relative to repo root)\n")
-	sb.WriteString("# Supports glob patterns and directories\n")
+	sb.WriteString("# Files to copy from repo root into new worktrees (array or comma-separated string, relative to repo root)\n")
+	sb.WriteString("# Supports glob patterns, ** for any depth, !pattern exclusions, and directories\n")
 	sb.WriteString(fmt.Sprintf("copy_files = %s\n\n", copyFilesValue))
 
+	sb.WriteString("# Copy git-ignored files matching these patterns (optional, same syntax as copy_files)\n")
+	sb.WriteString("# Example: copy_ignored = [\"**/.env*\", \".claude/**\", \"!**/node_modules/**\"]\n\n")
+
+	sb.WriteString("# Check out only these directories in new worktrees (optional, cone-mode sparse checkout)\n")
+	sb.WriteString("# Example: sparse_paths = [\"packages/api\", \"libs/shared\"]\n\n")
+
+	sb.WriteString("# Initialize submodules (reusing the main checkout's objects) and pull Git LFS files (optional)\n")
+	sb.WriteString("# init_submodules = true\n")
+	sb.WriteString("# lfs_pull = true\n\n")
+
 	sb.WriteString("# Command to run after worktree setup, before tmux session (optional)\n")
 	sb.WriteString("# Runs in worktree directory. Use for setup tasks like: bundle install, npm install\n")
 	sb.WriteString(fmt.Sprintf("pre_session_cmd = %s\n\n", preSessionValue))
 
+	sb.WriteString("# Where pre_session_cmd runs (optional): block (before the session, default),\n")
+	sb.WriteString("# window (a \"setup\" tmux window) or agent (the agent window, before --run)\n")
+	sb.WriteString("# pre_session_mode = \"window\"\n")
+	sb.WriteString("# Kill pre_session_cmd after this long (optional, e.g. \"90s\", \"10m\")\n")
+	sb.WriteString("# pre_session_timeout = \"10m\"\n\n")
+
+	sb.WriteString("# Fetch the base branch before mxt new and branch from <remote>/<base> (optional)\n")
+	sb.WriteString("# fetch_before_new = true\n")
+	sb.WriteString("# remote = \"upstream\"     # default: origin\n")
+	sb.WriteString("# base_branch = \"develop\" # default: <remote>/HEAD, then main/master\n\n")
+
 	sb.WriteString("# Tmux layout - define windows and panes (optional)\n")
 	sb.WriteString("# Multi-line format (more readable):\n")
 	sb.WriteString("# tmux_layout = \"\"\"\n")
@@ -371,6 +428,7 @@ func generateGlobalConfigContent(worktreeDir, terminal, sandboxTool, copyFiles,
 	sb.WriteString("#   agent:\n")
 	sb.WriteString("# \"\"\"\n")
 	sb.WriteString("# Or single line: tmux_layout = \"dev:hx|lazygit,server:bin/server,agent:\"\n")
+	sb.WriteString("# Or an array, one window per entry: tmux_layout = [\"dev:hx|lazygit\", \"agent:\"]\n")
 	sb.WriteString("#\n")
 	sb.WriteString("# Syntax:\n")
 	sb.WriteString("# - ',' or newline separates windows\n")
@@ -378,7 +436,13 @@ func generateGlobalConfigContent(worktreeDir, terminal, sandboxTool, copyFiles,
 	sb.WriteString("# - '|' separates panes (vertical split - side by side)\n")
 	sb.WriteString("# - Empty command = shell prompt\n")
 	sb.WriteString("# If not set, creates default layout: dev + agent windows\n")
-	sb.WriteString(fmt.Sprintf("tmux_layout = %s\n", tmuxLayoutValue))
+	sb.WriteString(fmt.Sprintf("tmux_layout = %s\n\n", tmuxLayoutValue))
+
+	sb.WriteString("# Lifecycle hooks (optional): post_create, pre_delete, post_delete, on_session_open\n")
+	sb.WriteString("# Run via sh -c with MXT_HOOK_BRANCH, MXT_HOOK_WORKTREE_PATH, MXT_HOOK_SESSION_NAME, ... set\n")
+	sb.WriteString("# [hooks]\n")
+	sb.WriteString("# post_create = \"bin/provision-db\"\n")
+	sb.WriteString("# pre_delete = { command = \"bin/dump-db\", on_failure = \"abort\" }  # abort | warn | prompt\n")
 
 	return sb.String(), nil
 }
@@ -406,9 +470,20 @@ func generateProjectConfigContent(copyFiles, sandboxTool, preSessionCmd, tmuxLay
 	var sb strings.Builder
 	sb.WriteString("# mxt project config (TOML)\n")
 	sb.WriteString(fmt.Sprintf("# Generated on %s\n\n", timestamp))
-	sb.WriteString("# Files to copy from repo root into new worktrees (comma-separated, relative to repo root)\n")
-	sb.WriteString("# Supports glob patterns and directories\n")
+	sb.WriteString("# Files to copy from repo root into new worktrees (array or comma-separated string, relative to repo root)\n")
+	sb.WriteString("# Supports glob patterns, ** for any depth, !pattern exclusions, and directories\n")
+	sb.WriteString("# Added to the global list; write copy_files = { replace = [...] } to replace it\n")
 	sb.WriteString(fmt.Sprintf("copy_files = %s\n\n", copyFilesValue))
+
+	sb.WriteString("# Copy git-ignored files matching these patterns (optional, same syntax as copy_files)\n")
+	sb.WriteString("# Example: copy_ignored = [\"**/.env*\", \".claude/**\", \"!**/node_modules/**\"]\n\n")
+
+	sb.WriteString("# Check out only these directories in new worktrees (optional, cone-mode sparse checkout)\n")
+	sb.WriteString("# Example: sparse_paths = [\"packages/api\", \"libs/shared\"]\n\n")
+
+	sb.WriteString("# Initialize submodules (reusing the main checkout's objects) and pull Git LFS files (optional)\n")
+	sb.WriteString("# init_submodules = true\n")
+	sb.WriteString("# lfs_pull = true\n\n")
 	sb.WriteString("# Optional sandbox tool command prefix for tmux sessions\n")
 	sb.WriteString("# Example: firejail --private, docker run --rm -it ...\n")
 	sb.WriteString(fmt.Sprintf("sandbox_tool = %s\n\n", sandboxValue))
@@ -417,6 +492,17 @@ func generateProjectConfigContent(copyFiles, sandboxTool, preSessionCmd, tmuxLay
 	sb.WriteString("# Runs in worktree directory. Use for setup tasks like: bundle install, npm install\n")
 	sb.WriteString(fmt.Sprintf("pre_session_cmd = %s\n\n", preSessionValue))
 
+	sb.WriteString("# Where pre_session_cmd runs (optional): block (before the session, default),\n")
+	sb.WriteString("# window (a \"setup\" tmux window) or agent (the agent window, before --run)\n")
+	sb.WriteString("# pre_session_mode = \"window\"\n")
+	sb.WriteString("# Kill pre_session_cmd after this long (optional, e.g. \"90s\", \"10m\")\n")
+	sb.WriteString("# pre_session_timeout = \"10m\"\n\n")
+
+	sb.WriteString("# Fetch the base branch before mxt new and branch from <remote>/<base> (optional)\n")
+	sb.WriteString("# fetch_before_new = true\n")
+	sb.WriteString("# remote = \"upstream\"     # default: origin\n")
+	sb.WriteString("# base_branch = \"develop\" # default: <remote>/HEAD, then main/master\n\n")
+
 	sb.WriteString("# Tmux layout - define windows and panes (optional)\n")
 	sb.WriteString("# Multi-line format (more readable):\n")
 	sb.WriteString("# tmux_layout = \"\"\"\n")
@@ -425,6 +511,7 @@ func generateProjectConfigContent(copyFiles, sandboxTool, preSessionCmd, tmuxLay
 	sb.WriteString("#   agent:\n")
 	sb.WriteString("# \"\"\"\n")
 	sb.WriteString("# Or single line: tmux_layout = \"dev:hx|lazygit,server:bin/server,agent:\"\n")
+	sb.WriteString("# Or an array, one window per entry: tmux_layout = [\"dev:hx|lazygit\", \"agent:\"]\n")
 	sb.WriteString("#\n")
 	sb.WriteString("# Syntax:\n")
 	sb.WriteString("# - ',' or newline separates windows\n")
@@ -432,7 +519,13 @@ func generateProjectConfigContent(copyFiles, sandboxTool, preSessionCmd, tmuxLay
 	sb.WriteString("# - '|' separates panes (vertical split - side by side)\n")
 	sb.WriteString("# - Empty command = shell prompt\n")
 	sb.WriteString("# If not set, creates default layout: dev + agent windows\n")
-	sb.WriteString(fmt.Sprintf("tmux_layout = %s\n", tmuxLayoutValue))
+	sb.WriteString(fmt.Sprintf("tmux_layout = %s\n\n", tmuxLayoutValue))
+
+	sb.WriteString("# Lifecycle hooks (optional): post_create, pre_delete, post_delete, on_session_open\n")
+	sb.WriteString("# Run via sh -c with MXT_HOOK_BRANCH, MXT_HOOK_WORKTREE_PATH, MXT_HOOK_SESSION_NAME, ... set\n")
+	sb.WriteString("# [hooks]\n")
+	sb.WriteString("# post_create = \"bin/provision-db\"\n")
+	sb.WriteString("# pre_delete = { command = \"bin/dump-db\", on_failure = \"abort\" }  # abort | warn | prompt\n")
 
 	return sb.String(), nil
 }
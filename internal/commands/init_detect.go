@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectDetection is what mxt init --local proposes for a repository.
+type projectDetection struct {
+	types         []string // e.g. "Node.js (pnpm)", for display
+	copyFiles     []string
+	preSessionCmd string
+}
+
+// projectType recognises a kind of project by a marker file in the repo root.
+// Its lockfiles pick the install command; install is used without one.
+type projectType struct {
+	name      string
+	marker    string
+	install   string
+	lockfiles []projectLockfile
+}
+
+type projectLockfile struct {
+	file    string
+	tool    string
+	install string
+}
+
+var projectTypes = []projectType{
+	{name: "Node.js", marker: "package.json", install: "npm install", lockfiles: []projectLockfile{
+		{"pnpm-lock.yaml", "pnpm", "pnpm install"},
+		{"yarn.lock", "yarn", "yarn install"},
+		{"bun.lock", "bun", "bun install"},
+		{"bun.lockb", "bun", "bun install"},
+	}},
+	{name: "Ruby", marker: "Gemfile", install: "bundle install"},
+	{name: "Go", marker: "go.mod", install: "go mod download"},
+	{name: "Python", marker: "pyproject.toml", lockfiles: []projectLockfile{
+		{"uv.lock", "uv", "uv sync"},
+		{"poetry.lock", "poetry", "poetry install"},
+	}},
+}
+
+// detectProject looks at the files in root to propose copy_files and
+// pre_session_cmd. ignored lists the git-ignored files (relative to root);
+// the .env files and CLAUDE.md among them are proposed for copy_files, since
+// a new worktree won't have them.
+func detectProject(root string, ignored []string) projectDetection {
+	var detected projectDetection
+	var commands []string
+	for _, project := range projectTypes {
+		if !fileExists(filepath.Join(root, project.marker)) {
+			continue
+		}
+		name, install := project.name, project.install
+		for _, lockfile := range project.lockfiles {
+			if fileExists(filepath.Join(root, lockfile.file)) {
+				name = project.name + " (" + lockfile.tool + ")"
+				install = lockfile.install
+				break
+			}
+		}
+		detected.types = append(detected.types, name)
+		if install != "" {
+			commands = append(commands, install)
+		}
+	}
+	detected.preSessionCmd = strings.Join(commands, " && ")
+
+	for _, file := range ignored {
+		if strings.Contains(file, "/") {
+			continue
+		}
+		if strings.HasPrefix(file, ".env") || file == "CLAUDE.md" {
+			detected.copyFiles = append(detected.copyFiles, file)
+		}
+	}
+	return detected
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectProject(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         []string
+		ignored       []string
+		expectTypes   []string
+		expectCopy    []string
+		expectCommand string
+	}{
+		{
+			name: "empty repo",
+		},
+		{
+			name:          "npm",
+			files:         []string{"package.json"},
+			expectTypes:   []string{"Node.js"},
+			expectCommand: "npm install",
+		},
+		{
+			name:          "pnpm",
+			files:         []string{"package.json", "pnpm-lock.yaml"},
+			expectTypes:   []string{"Node.js (pnpm)"},
+			expectCommand: "pnpm install",
+		},
+		{
+			name:          "rails app with yarn",
+			files:         []string{"Gemfile", "package.json", "yarn.lock"},
+			expectTypes:   []string{"Node.js (yarn)", "Ruby"},
+			expectCommand: "yarn install && bundle install",
+		},
+		{
+			name:          "go",
+			files:         []string{"go.mod"},
+			expectTypes:   []string{"Go"},
+			expectCommand: "go mod download",
+		},
+		{
+			name:          "python with uv",
+			files:         []string{"pyproject.toml", "uv.lock"},
+			expectTypes:   []string{"Python (uv)"},
+			expectCommand: "uv sync",
+		},
+		{
+			name:        "python without a lockfile",
+			files:       []string{"pyproject.toml"},
+			expectTypes: []string{"Python"},
+		},
+		{
+			name:       "ignored env files and CLAUDE.md",
+			ignored:    []string{".env", ".env.local", "CLAUDE.md", "node_modules/x/.env", "tmp/CLAUDE.md", "debug.log"},
+			expectCopy: []string{".env", ".env.local", "CLAUDE.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, file), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			detected := detectProject(root, tt.ignored)
+			if !reflect.DeepEqual(detected.types, tt.expectTypes) {
+				t.Errorf("types = %q, want %q", detected.types, tt.expectTypes)
+			}
+			if !reflect.DeepEqual(detected.copyFiles, tt.expectCopy) {
+				t.Errorf("copyFiles = %q, want %q", detected.copyFiles, tt.expectCopy)
+			}
+			if detected.preSessionCmd != tt.expectCommand {
+				t.Errorf("preSessionCmd = %q, want %q", detected.preSessionCmd, tt.expectCommand)
+			}
+		})
+	}
+}
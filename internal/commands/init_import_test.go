@@ -3,6 +3,7 @@ package commands
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -31,17 +32,14 @@ func TestImportLegacyConfigWritesToml(t *testing.T) {
 		t.Fatalf("ParseConfig() error = %v", err)
 	}
 
-	expected := map[string]string{
-		"worktree_dir":    "~/worktrees",
-		"terminal":        "iterm2",
-		"copy_files":      ".env,.env.local",
-		"pre_session_cmd": "echo hi",
-		"tmux_layout":     "dev:hx|lazygit;server:bin/server;agent:",
-	}
-	for key, expectedValue := range expected {
-		if parsed[key] != expectedValue {
-			t.Errorf("imported config[%s] = %q, want %q", key, parsed[key], expectedValue)
-		}
+	if *parsed.WorktreeDir != "~/worktrees" || *parsed.Terminal != "iterm2" || *parsed.PreSessionCmd != "echo hi" {
+		t.Errorf("imported config = worktree_dir %q, terminal %q, pre_session_cmd %q", *parsed.WorktreeDir, *parsed.Terminal, *parsed.PreSessionCmd)
+	}
+	if expected := []string{".env", ".env.local"}; !reflect.DeepEqual(parsed.CopyFiles, expected) {
+		t.Errorf("imported copy_files = %q, want %q", parsed.CopyFiles, expected)
+	}
+	if expected := []string{"dev:hx|lazygit", "server:bin/server", "agent:"}; !reflect.DeepEqual(parsed.TmuxLayout, expected) {
+		t.Errorf("imported tmux_layout = %q, want %q", parsed.TmuxLayout, expected)
 	}
 }
 
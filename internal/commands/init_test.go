@@ -4,8 +4,12 @@ import (
 	"bufio"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
 )
 
 type failReader struct {
@@ -50,3 +54,87 @@ func TestShouldOverwriteConfigPromptNo(t *testing.T) {
 		t.Fatal("expected overwrite to be rejected when user declines")
 	}
 }
+
+func TestInitPrompterAsk(t *testing.T) {
+	given := "vim"
+	tests := []struct {
+		name     string
+		input    string
+		answer   *string
+		skip     bool
+		def      string
+		expected string
+	}{
+		{"typed answer", "ghostty\n", nil, false, "terminal", "ghostty"},
+		{"empty line takes the default", "\n", nil, false, "terminal", "terminal"},
+		{"none clears the default", "none\n", nil, false, "npm install", ""},
+		{"flag answer", "", &given, false, "nano", "vim"},
+		{"flag answer with skip", "", &given, true, "nano", "vim"},
+		{"skip takes the default", "", nil, true, "npm install", "npm install"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReader(strings.NewReader(tt.input))
+			if tt.input == "" {
+				reader = bufio.NewReader(&failReader{})
+			}
+			prompter := &initPrompter{reader: reader, skip: tt.skip}
+			if got := prompter.ask(tt.answer, "Label", tt.def); got != tt.expected {
+				t.Errorf("ask() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestInitLocalYesAcceptsDetectedDefaults runs mxt init --local --yes with
+// answers waiting on stdin, and checks that none are read
+func TestInitLocalYesAcceptsDetectedDefaults(t *testing.T) {
+	t.Setenv("MXT_CONFIG_DIR", t.TempDir())
+	repo := t.TempDir()
+	testutil.NewRepo(t, repo)
+	for name, content := range map[string]string{
+		".gitignore":     ".env\n",
+		".env":           "SECRET=1\n",
+		"package.json":   "{}\n",
+		"pnpm-lock.yaml": "",
+	} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(repo)
+
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.WriteString(strings.Repeat("none\n", 4)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	if err := InitCommand(true, false, false, InitAnswers{Defaults: true}); err != nil {
+		t.Fatalf("InitCommand() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(repo, ".mxt.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"copy_files = '.env'", "pre_session_cmd = 'pnpm install'"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf(".mxt.toml missing %q:\n%s", want, content)
+		}
+	}
+
+	// An existing config still needs --reinit
+	err = InitCommand(true, false, false, InitAnswers{Defaults: true})
+	if err == nil || !strings.Contains(err.Error(), "--reinit") {
+		t.Errorf("InitCommand() over an existing config error = %v, want --reinit hint", err)
+	}
+}
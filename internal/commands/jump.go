@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/tmux"
+	"github.com/gkarolyi/mxt/internal/ui"
+)
+
+// JumpCommand picks any running mxt session on the machine with fzf and
+// attaches to it (or switches to it when already inside tmux). Sessions are
+// found by scanning every repository under $WORKTREE_DIR, so it works from
+// any directory.
+func JumpCommand() error {
+	// Step 1: Load configuration (no repository required)
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Step 2: Collect active sessions of all managed worktrees
+	worktrees, err := scanGlobalWorktrees(cfg.WorktreeDir)
+	if err != nil {
+		return err
+	}
+	var items []string
+	for _, wt := range worktrees {
+		if wt.mainRepo == "" {
+			continue
+		}
+		sessionName := worktreeSessionName(wt.repoName, wt.path)
+		if tmux.HasSession(sessionName, cfg.SandboxTool) {
+			items = append(items, formatJumpItem(sessionName, wt))
+		}
+	}
+	if len(items) == 0 {
+		ui.Info(noActiveSessionsMessage)
+		return nil
+	}
+
+	// Step 3: Select a session
+	if !isInteractive() {
+		return errors.New("mxt jump needs an interactive terminal. Use mxt sessions attach <branch> instead.")
+	}
+	selection, cancelled, err := selectWithFzf(items)
+	if err != nil {
+		return err
+	}
+	if cancelled {
+		ui.Info(selectionCancelledMsg)
+		return nil
+	}
+
+	// Step 4: Attach (or switch client inside tmux)
+	return tmux.SwitchToSession(parseJumpSession(selection), cfg.SandboxTool)
+}
+
+// formatJumpItem formats a picker line: the session name, then repository,
+// branch and path for context.
+func formatJumpItem(sessionName string, wt globalWorktree) string {
+	branch := wt.branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+	return fmt.Sprintf("%s\t%s  %s  %s", sessionName, wt.repoName, branch, wt.path)
+}
+
+// parseJumpSession returns the session name from a picker line.
+func parseJumpSession(selection string) string {
+	sessionName, _, _ := strings.Cut(strings.TrimSpace(selection), "\t")
+	return sessionName
+}
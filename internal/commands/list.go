@@ -3,7 +3,6 @@ package commands
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -13,6 +12,7 @@ import (
 	"github.com/gkarolyi/mxt/internal/config"
 	"github.com/gkarolyi/mxt/internal/git"
 	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
 )
 
 // WorktreeInfo represents information about a single worktree.
@@ -23,12 +23,37 @@ type WorktreeInfo struct {
 	Deletions     int
 	SessionName   string
 	SessionActive bool
+	SetupStatus   string   // Recorded pre_session_cmd status (window/agent modes); empty if none
+	SparsePaths   []string // Cone-mode sparse-checkout directories; nil for a full checkout
+	Unmanaged     bool     // Outside $WORKTREE_DIR/<repo>/ (only listed with --all-worktrees)
+	MainCheckout  bool     // The repository's main checkout (only listed with --all-worktrees)
+
+	// Detached worktrees have an empty BranchName and are addressed by path
+	Detached       bool
+	Head           string // Commit checked out
+	Locked         bool
+	LockReason     string
+	Prunable       bool // Directory is gone; git worktree prune removes the entry
+	PrunableReason string
+}
+
+// Target returns the name commands accept for this worktree: its branch, or
+// its path when HEAD is detached.
+func (wt WorktreeInfo) Target() string {
+	if wt.BranchName != "" {
+		return wt.BranchName
+	}
+	return wt.Path
 }
 
 // ListCommand lists all managed worktrees for the current repository.
-func ListCommand() error {
-	// Step 1: Check if inside git repository
-	if !git.IsInsideWorkTree() {
+// When allWorktrees is set, the main checkout and worktrees created outside
+// mxt are listed too, marked as unmanaged. When global is set, the worktrees
+// of every repository under $WORKTREE_DIR are listed instead, and the command
+// works outside a repository.
+func ListCommand(allWorktrees, global bool) error {
+	// Step 1: Check if inside git repository (not needed for --global)
+	if !global && !git.IsInsideWorkTree() {
 		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
 	}
 
@@ -37,6 +62,9 @@ func ListCommand() error {
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	if global {
+		return listGlobal(cfg.WorktreeDir)
+	}
 
 	// Step 3: Get repository name
 	repoName, err := git.GetRepoName()
@@ -48,14 +76,8 @@ func ListCommand() error {
 	fmt.Printf("%sWorktrees for %s\n", ui.Bold, ui.CyanText(repoName))
 	fmt.Println("════════════════════════════════════════════════════════════════")
 
-	managedDir := filepath.Join(cfg.WorktreeDir, repoName)
-	if _, err := os.Stat(managedDir); os.IsNotExist(err) {
-		ui.Info(fmt.Sprintf("No worktrees found. Use %s to create one.", ui.BoldText("mxt new [branch]")))
-		return nil
-	}
-
-	// Step 5: Get managed worktrees
-	worktrees, err := getManagedWorktrees(cfg.WorktreeDir, repoName)
+	// Step 5: Get managed worktrees (and unmanaged ones with --all-worktrees)
+	worktrees, err := listWorktrees(cfg.WorktreeDir, repoName, allWorktrees)
 	if err != nil {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
@@ -79,61 +101,54 @@ func ListCommand() error {
 
 // getManagedWorktrees returns a list of worktrees managed by mxt (in $WORKTREE_DIR/<repo>/).
 func getManagedWorktrees(worktreeDir, repoName string) ([]WorktreeInfo, error) {
-	// Run git worktree list --porcelain
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
+	return listWorktrees(worktreeDir, repoName, false)
+}
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("git worktree list failed: %w", err)
+// listWorktrees returns the repository's worktrees, including detached,
+// locked and prunable ones. Only managed worktrees are returned unless
+// includeUnmanaged is set, in which case the main checkout and worktrees
+// outside $WORKTREE_DIR/<repo>/ (see isManagedWorktree) are included too
+// (marked Unmanaged).
+func listWorktrees(worktreeDir, repoName string, includeUnmanaged bool) ([]WorktreeInfo, error) {
+	entries, err := git.ListWorktrees()
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse output
-	lines := strings.Split(stdout.String(), "\n")
 	var worktrees []WorktreeInfo
-	managedBase := filepath.Join(worktreeDir, repoName)
-
-	var currentPath string
-	var currentBranch string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.HasPrefix(line, "worktree ") {
-			// Save previous worktree if it was managed
-			if currentPath != "" && currentBranch != "" && strings.HasPrefix(currentPath, managedBase) {
-				wt := createWorktreeInfo(currentPath, currentBranch, repoName)
-				worktrees = append(worktrees, wt)
-			}
-
-			// Start new worktree
-			currentPath = strings.TrimPrefix(line, "worktree ")
-			currentBranch = ""
-		} else if strings.HasPrefix(line, "branch ") {
-			// Extract branch name from refs/heads/<branch>
-			refPath := strings.TrimPrefix(line, "branch ")
-			currentBranch = strings.TrimPrefix(refPath, "refs/heads/")
-		} else if line == "" {
-			// End of worktree entry - save it if managed
-			if currentPath != "" && currentBranch != "" && strings.HasPrefix(currentPath, managedBase) {
-				wt := createWorktreeInfo(currentPath, currentBranch, repoName)
-				worktrees = append(worktrees, wt)
-			}
-			currentPath = ""
-			currentBranch = ""
+	for i, entry := range entries {
+		if entry.Bare {
+			continue
+		}
+		managed := i > 0 && isManagedWorktree(worktreeDir, repoName, entry)
+		if !managed && !includeUnmanaged {
+			continue
+		}
+		wt := createWorktreeInfo(entry.Path, entry.Branch, repoName)
+		wt.Detached = entry.Detached
+		wt.Head = entry.Head
+		wt.Locked = entry.Locked
+		wt.LockReason = entry.LockReason
+		wt.Prunable = entry.Prunable
+		wt.PrunableReason = entry.PrunableReason
+		if !managed {
+			wt.Unmanaged = true
+			wt.MainCheckout = i == 0 // git lists the main checkout first
 		}
-	}
-
-	// Handle last worktree if file doesn't end with blank line
-	if currentPath != "" && currentBranch != "" && strings.HasPrefix(currentPath, managedBase) {
-		wt := createWorktreeInfo(currentPath, currentBranch, repoName)
 		worktrees = append(worktrees, wt)
 	}
 
 	return worktrees, nil
 }
 
+// isWithinDir reports whether path is inside dir (not dir itself, and not a
+// sibling that merely shares its prefix, like <dir>-other).
+func isWithinDir(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
 // createWorktreeInfo creates a WorktreeInfo from path and branch, calculating stats and session status.
+// branch is empty for a detached HEAD.
 func createWorktreeInfo(path, branch, repoName string) WorktreeInfo {
 	wt := WorktreeInfo{
 		BranchName: branch,
@@ -146,10 +161,18 @@ func createWorktreeInfo(path, branch, repoName string) WorktreeInfo {
 	wt.Deletions = deletions
 
 	// Check session status
-	sessionName := git.GenerateSessionName(repoName, branch)
+	sessionName := worktreeSessionName(repoName, path)
 	wt.SessionName = sessionName
 	wt.SessionActive = isSessionActive(sessionName)
 
+	// Read sparse-checkout directories
+	wt.SparsePaths = worktree.SparsePaths(path)
+
+	// Read pre_session_cmd status recorded by window/agent modes
+	if status, ok := worktree.ReadSetupStatus(path); ok {
+		wt.SetupStatus = status
+	}
+
 	return wt
 }
 
@@ -229,15 +252,50 @@ func isSessionActive(sessionName string) bool {
 
 // displayWorktree prints information about a single worktree.
 func displayWorktree(wt WorktreeInfo) {
-	// Line 1: Branch name + change stats
+	// Line 1: Branch name + change stats (+ unmanaged/locked/prunable markers)
 	branchText := ui.BoldText(ui.CyanText(wt.BranchName))
+	if wt.BranchName == "" {
+		branchText = ui.YellowText(fmt.Sprintf("(detached at %s)", shortCommit(wt.Head)))
+	}
 	insertionsText := ui.GreenText(fmt.Sprintf("+%d", wt.Insertions))
 	deletionsText := ui.RedText(fmt.Sprintf("-%d", wt.Deletions))
-	fmt.Printf("  %s  %s %s\n", branchText, insertionsText, deletionsText)
+	marker := ""
+	if wt.MainCheckout {
+		marker = "  " + ui.DimText("(main checkout)")
+	} else if wt.Unmanaged {
+		marker = "  " + ui.YellowText("(unmanaged)")
+	}
+	if wt.Locked {
+		marker += "  " + ui.YellowText("[locked]")
+	}
+	if wt.Prunable {
+		marker += "  " + ui.RedText("[prunable]")
+	}
+	fmt.Printf("  %s  %s %s%s\n", branchText, insertionsText, deletionsText, marker)
 
 	// Line 2: Worktree path
 	fmt.Printf("  %s\n", ui.DimText(wt.Path))
 
+	// Lock reason and prunable reason (when set)
+	if wt.Locked && wt.LockReason != "" {
+		fmt.Printf("  Locked:  %s\n", wt.LockReason)
+	}
+	if wt.Prunable {
+		reason := wt.PrunableReason
+		if reason == "" {
+			reason = "worktree directory is missing"
+		}
+		fmt.Printf("  Prunable: %s %s\n", reason, ui.DimText("(git worktree prune removes it)"))
+	}
+
+	// Unmanaged worktrees have no mxt session; show how to adopt them instead
+	if wt.Unmanaged {
+		if !wt.MainCheckout && !wt.Detached {
+			fmt.Printf("  Adopt:   %s\n", ui.DimText("mxt adopt "+wt.BranchName))
+		}
+		return
+	}
+
 	// Line 3: Session status
 	var statusSymbol string
 	if wt.SessionActive {
@@ -246,4 +304,28 @@ func displayWorktree(wt WorktreeInfo) {
 		statusSymbol = ui.DimText("○")
 	}
 	fmt.Printf("  Session: %s %s\n", statusSymbol, wt.SessionName)
+
+	// Line 4: Sparse-checkout set (only for sparse worktrees)
+	if len(wt.SparsePaths) > 0 {
+		fmt.Printf("  Sparse:  %s\n", ui.DimText(strings.Join(wt.SparsePaths, ", ")))
+	}
+
+	// Line 5: Pre-session command status (only when recorded)
+	if wt.SetupStatus != "" {
+		fmt.Printf("  Setup:   %s\n", formatSetupStatus(wt.SetupStatus))
+	}
+}
+
+// formatSetupStatus renders a recorded pre_session_cmd status with a symbol.
+func formatSetupStatus(status string) string {
+	switch status {
+	case worktree.SetupRunning:
+		return ui.YellowText("◐ running")
+	case worktree.ExitStatus(0):
+		return ui.GreenText("✓ " + status)
+	case worktree.SetupTimedOut:
+		return ui.RedText("✗ timed out")
+	default:
+		return ui.RedText("✗ " + status)
+	}
 }
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+// LockCommand locks a managed worktree (by branch, or by path) so that mxt
+// delete and finish refuse to remove it and git worktree prune/move/remove
+// leave it alone. Use it for long-lived worktrees such as release branches or
+// worktrees on removable drives.
+func LockCommand(branchOrPath, reason string) error {
+	target, err := loadWorktreeTarget(branchOrPath)
+	if err != nil {
+		return err
+	}
+
+	locked, existing, err := worktree.LockStatus(target.path)
+	if err != nil {
+		return err
+	}
+	if locked {
+		ui.Info(fmt.Sprintf("%s is already locked%s", target.path, formatLockReason(existing)))
+		return nil
+	}
+	if err := worktree.Lock(target.path, reason); err != nil {
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Locked %s%s", ui.CyanText(target.path), formatLockReason(reason)))
+	return nil
+}
+
+// UnlockCommand removes the lock from a managed worktree.
+func UnlockCommand(branchOrPath string) error {
+	target, err := loadWorktreeTarget(branchOrPath)
+	if err != nil {
+		return err
+	}
+
+	locked, _, err := worktree.LockStatus(target.path)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		ui.Info(fmt.Sprintf("%s is not locked", target.path))
+		return nil
+	}
+	if err := worktree.Unlock(target.path); err != nil {
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Unlocked %s", ui.CyanText(target.path)))
+	return nil
+}
+
+// loadWorktreeTarget runs the prerequisite checks, loads configuration and
+// resolves a branch or path to a managed worktree.
+func loadWorktreeTarget(branchOrPath string) (worktreeTarget, error) {
+	if !git.IsInsideWorkTree() {
+		return worktreeTarget{}, fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	repoName, err := git.GetRepoName()
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("failed to get repository name: %w", err)
+	}
+
+	return resolveWorktreeTarget(cfg.WorktreeDir, repoName, branchOrPath)
+}
+
+// checkNotLocked returns an error if the worktree at path is locked, telling
+// the user how to unlock it. branchOrPath is what they passed on the command line.
+func checkNotLocked(path, branchOrPath string) error {
+	locked, reason, err := worktree.LockStatus(path)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return fmt.Errorf("Worktree is locked%s. Run mxt unlock %s first.", formatLockReason(reason), branchOrPath)
+	}
+	return nil
+}
+
+// formatLockReason formats a lock reason for display, e.g. " (release freeze)".
+func formatLockReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", reason)
+}
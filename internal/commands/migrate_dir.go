@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/tmux"
+	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+// dirMove is one worktree to relocate into the current worktree_dir.
+type dirMove struct {
+	branch  string // Empty for a detached worktree
+	oldPath string
+	newPath string
+}
+
+// target returns the name other commands accept for the moved worktree.
+func (m dirMove) target() string {
+	if m.branch != "" {
+		return m.branch
+	}
+	return m.newPath
+}
+
+// MigrateDirCommand moves the repository's worktrees from an old worktree_dir
+// to the current one.
+//
+// fromDir is the previous worktree_dir. When empty, every linked worktree laid
+// out as <dir>/<repo>/<sanitized-branch> outside the current worktree_dir is
+// migrated. Worktrees that were already moved by hand (so git's links point at
+// the old path) are repaired in place. Session names don't depend on
+// worktree_dir, so running sessions keep their names.
+func MigrateDirCommand(fromDir string, dryRun bool) error {
+	// Step 1: Prerequisite checks
+	if !git.IsInsideWorkTree() {
+		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+
+	// Step 2: Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	repoName, err := git.GetRepoName()
+	if err != nil {
+		return fmt.Errorf("failed to get repository name: %w", err)
+	}
+
+	if fromDir != "" {
+		fromDir, err = filepath.Abs(config.ExpandTilde(fromDir))
+		if err != nil {
+			return fmt.Errorf("invalid --from directory: %w", err)
+		}
+	}
+
+	// Step 3: Plan the moves
+	entries, err := git.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	// Each worktree belongs under the worktree_dir of its branch's rules and
+	// the profile it was created with
+	worktreeDirFor := func(entry git.Worktree) string {
+		profile, _ := worktree.ReadProfile(entry.Path)
+		if wtCfg, err := config.LoadFor(entry.Branch, profile); err == nil {
+			return wtCfg.WorktreeDir
+		}
+		return cfg.WorktreeDir
+	}
+	moves, locked := planDirMigration(entries, worktreeDirFor, fromDir, repoName)
+	for _, entry := range locked {
+		ui.Warn(fmt.Sprintf("Skipping %s: it is locked%s. Run git worktree unlock %s to migrate it.", entry.Path, formatLockReason(entry.LockReason), entry.Path))
+	}
+	if len(moves) == 0 {
+		if len(locked) == 0 {
+			ui.Info(fmt.Sprintf("All worktrees are already under %s", filepath.Join(cfg.WorktreeDir, repoName)))
+		}
+		return nil
+	}
+
+	// Step 4: Show the plan
+	fmt.Println()
+	for _, move := range moves {
+		fmt.Printf("  %s\n", ui.BoldText(ui.CyanText(filepath.Base(move.target()))))
+		fmt.Printf("    %s\n", ui.DimText(move.oldPath))
+		fmt.Printf("    → %s\n", move.newPath)
+	}
+	fmt.Println()
+	if dryRun {
+		ui.Info(fmt.Sprintf("Dry run: %d worktree(s) would be moved", len(moves)))
+		return nil
+	}
+
+	// Step 5: Move each worktree (or repair one that was moved by hand)
+	var failures []error
+	var moved []dirMove
+	for _, move := range moves {
+		if err := migrateWorktree(move); err != nil {
+			ui.Warn(fmt.Sprintf("%s: %v", move.oldPath, err))
+			failures = append(failures, fmt.Errorf("%s: %w", move.oldPath, err))
+			continue
+		}
+		ui.Success(fmt.Sprintf("Moved %s", ui.CyanText(filepath.Base(move.newPath))))
+		moved = append(moved, move)
+		cleanupRepoDir(filepath.Dir(filepath.Dir(move.oldPath)), repoName)
+	}
+
+	// Step 6: Repair git links in both directions for the moved worktrees
+	if len(moved) > 0 {
+		paths := make([]string, len(moved))
+		for i, move := range moved {
+			paths[i] = move.newPath
+		}
+		if err := worktree.Repair(paths...); err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	// Step 7: Point out running sessions whose shells still use the old paths
+	for _, move := range moved {
+		sessionName := worktreeSessionName(repoName, move.newPath)
+		if tmux.HasSession(sessionName, cfg.SandboxTool) {
+			ui.Info(fmt.Sprintf("Session %s was started in the old path; run %s to start fresh shells", sessionName, ui.BoldText("mxt sessions relaunch "+move.target())))
+		}
+	}
+
+	// Step 8: Summary
+	fmt.Println()
+	if len(failures) > 0 {
+		return fmt.Errorf("Migrated %d of %d worktrees:\n%w", len(moved), len(moves), errors.Join(failures...))
+	}
+	ui.Success(fmt.Sprintf("Migrated %d worktree(s) to %s", len(moved), filepath.Join(cfg.WorktreeDir, repoName)))
+
+	return nil
+}
+
+// planDirMigration selects the linked worktrees to move into the worktree_dir
+// worktreeDirFor returns for them. With fromDir set, only worktrees under
+// <fromDir>/<repo>/ are selected; otherwise any worktree at
+// <dir>/<repo>/<sanitized-branch> (the layout mxt creates) outside its
+// worktree_dir is. Detached worktrees keep their directory
+// name and are only selected with fromDir, since their layout can't be
+// checked against a branch. The main checkout is never selected. Locked
+// worktrees that would be selected are returned separately and not moved.
+func planDirMigration(entries []git.Worktree, worktreeDirFor func(git.Worktree) string, fromDir, repoName string) (moves []dirMove, locked []git.Worktree) {
+	for i, entry := range entries {
+		if i == 0 || entry.Bare || (entry.Branch == "" && fromDir == "") {
+			continue
+		}
+		worktreeDir := worktreeDirFor(entry)
+		newPath := git.CalculateWorktreePath(worktreeDir, repoName, entry.Branch)
+		if entry.Branch == "" {
+			newPath = filepath.Join(worktreeDir, repoName, filepath.Base(entry.Path))
+		}
+		if samePath(entry.Path, newPath) {
+			continue
+		}
+		if fromDir != "" {
+			if !isWithinDir(entry.Path, filepath.Join(fromDir, repoName)) {
+				continue
+			}
+		} else if entry.Path != git.CalculateWorktreePath(filepath.Dir(filepath.Dir(entry.Path)), repoName, entry.Branch) {
+			continue
+		}
+		if entry.Locked {
+			locked = append(locked, entry)
+			continue
+		}
+		moves = append(moves, dirMove{branch: entry.Branch, oldPath: entry.Path, newPath: newPath})
+	}
+	return moves, locked
+}
+
+// migrateWorktree moves one worktree. If the old directory is gone and the new
+// one exists, the worktree was already moved by hand and only needs its git
+// links repaired, which MigrateDirCommand does for every moved worktree.
+func migrateWorktree(move dirMove) error {
+	_, oldErr := os.Stat(move.oldPath)
+	_, newErr := os.Stat(move.newPath)
+	switch {
+	case oldErr == nil && newErr == nil:
+		return fmt.Errorf("destination already exists: %s", move.newPath)
+	case oldErr != nil && newErr == nil:
+		return nil
+	case oldErr != nil:
+		return fmt.Errorf("worktree directory is missing: %s", move.oldPath)
+	}
+	return worktree.Move(move.oldPath, move.newPath)
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/git"
+)
+
+func TestPlanDirMigration(t *testing.T) {
+	entries := []git.Worktree{
+		{Path: "/src/app", Branch: "main"},
+		{Path: "/old/app/feature-auth", Branch: "feature/auth"},
+		{Path: "/new/app/fix-bug", Branch: "fix-bug"},
+		{Path: "/other/app/docs", Branch: "docs"},
+		{Path: "/src/app-hotfix", Branch: "hotfix"},
+		{Path: "/old/app/scratch", Detached: true},
+		{Path: "/profile/app/api", Branch: "api"},
+		{Path: "/old/app/web", Branch: "web"},
+		{Path: "/old/app/release", Branch: "release", Locked: true, LockReason: "usb drive"},
+	}
+	// api and web were created with a profile that sets worktree_dir = "/profile"
+	worktreeDirFor := func(entry git.Worktree) string {
+		if entry.Branch == "api" || entry.Branch == "web" {
+			return "/profile"
+		}
+		return "/new"
+	}
+
+	locked := []git.Worktree{entries[len(entries)-1]}
+
+	tests := []struct {
+		name     string
+		fromDir  string
+		expected []dirMove
+		locked   []git.Worktree
+	}{
+		{
+			name:    "detect mxt layout",
+			fromDir: "",
+			expected: []dirMove{
+				{branch: "feature/auth", oldPath: "/old/app/feature-auth", newPath: "/new/app/feature-auth"},
+				{branch: "docs", oldPath: "/other/app/docs", newPath: "/new/app/docs"},
+				{branch: "web", oldPath: "/old/app/web", newPath: "/profile/app/web"},
+			},
+			locked: locked,
+		},
+		{
+			name:    "only from the given directory",
+			fromDir: "/old",
+			expected: []dirMove{
+				{branch: "feature/auth", oldPath: "/old/app/feature-auth", newPath: "/new/app/feature-auth"},
+				{oldPath: "/old/app/scratch", newPath: "/new/app/scratch"},
+				{branch: "web", oldPath: "/old/app/web", newPath: "/profile/app/web"},
+			},
+			locked: locked,
+		},
+		{
+			name:     "nothing under the given directory",
+			fromDir:  "/elsewhere",
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotLocked := planDirMigration(entries, worktreeDirFor, tt.fromDir, "app")
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("planDirMigration() = %#v, want %#v", got, tt.expected)
+			}
+			if !reflect.DeepEqual(gotLocked, tt.locked) {
+				t.Errorf("planDirMigration() locked = %#v, want %#v", gotLocked, tt.locked)
+			}
+		})
+	}
+}
+
+func TestMigrateWorktreeChecksPaths(t *testing.T) {
+	base := t.TempDir()
+	existing := filepath.Join(base, "existing")
+	other := filepath.Join(base, "other")
+	missing := filepath.Join(base, "missing")
+	for _, dir := range []string{existing, other} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		move    dirMove
+		wantErr string
+	}{
+		{"destination exists", dirMove{oldPath: existing, newPath: other}, "destination already exists"},
+		{"already moved by hand", dirMove{oldPath: missing, newPath: other}, ""},
+		{"both missing", dirMove{oldPath: missing, newPath: missing + "-new"}, "worktree directory is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := migrateWorktree(tt.move)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("migrateWorktree() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("migrateWorktree() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
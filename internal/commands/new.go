@@ -7,13 +7,13 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"os/signal"
 	"strings"
 	"syscall"
 
 	"github.com/gkarolyi/mxt/internal/config"
 	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/hooks"
 	"github.com/gkarolyi/mxt/internal/sandbox"
 	"github.com/gkarolyi/mxt/internal/terminal"
 	"github.com/gkarolyi/mxt/internal/tmux"
@@ -26,36 +26,50 @@ import (
 //
 // Phase 4: Implements worktree creation, file copying, and pre-session command execution.
 // Phase 5: Will add tmux session creation and terminal opening.
-func NewCommand(branchName string, fromBranch string, runCmd string, bg bool) error {
+// When dryRun is set, it validates the request and prints the planned worktree
+// path and the files that copy_files would copy, without making any changes.
+// sparse is a comma-separated list of directories for a cone-mode sparse
+// checkout; when empty, sparse_paths from config is used. The [[rules]]
+// matching branchName apply, then profile, which names a [profiles.<name>]
+// table (default: default_profile); it is recorded in the worktree for mxt
+// sessions open and relaunch.
+func NewCommand(branchName string, fromBranch string, runCmd string, sparse string, profile string, bg bool, dryRun bool) error {
 	// Step 1: Prerequisite Checks
 	if !git.IsInsideWorkTree() {
 		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
 	}
 
-	// Step 2: Load configuration
-	cfg, err := config.Load()
+	// Step 2: Load configuration (with the branch's rules, and --profile or default_profile)
+	cfg, err := config.LoadFor(branchName, profile)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Step 3: Validate --run command
+	// Step 3: Validate --run command and tmux_layout
 	if runCmd != "" && runCmd != "claude" && runCmd != "codex" {
 		return fmt.Errorf("Invalid --run command: '%s'. Allowed: claude, codex", runCmd)
 	}
+	if _, err := validateLayout(cfg); err != nil {
+		return fmt.Errorf("Invalid tmux_layout: %v", err)
+	}
 
-	// Step 4: Determine base branch
+	// Step 4: Determine base branch (--from, then base_branch, then <remote>/HEAD)
 	baseBranch := fromBranch
 	if baseBranch == "" {
-		baseBranch = git.GetMainBranch()
+		baseBranch = cfg.BaseBranch
+	}
+	if baseBranch == "" {
+		baseBranch = git.GetDefaultBranch(cfg.Remote)
 	}
 
-	// Step 5: Validate base branch exists
-	if err := validateBranchExists(baseBranch); err != nil {
-		return fmt.Errorf("Base branch '%s' does not exist.", baseBranch)
+	// Step 5: Resolve start point (fetching first when fetch_before_new is set)
+	startPoint, err := resolveStartPoint(baseBranch, cfg.Remote, cfg.FetchBeforeNew, dryRun)
+	if err != nil {
+		return err
 	}
 
 	// Step 6: Check if new branch already exists
-	if err := validateBranchExists(branchName); err == nil {
+	if err := validateBranchExists(branchName, cfg.Remote); err == nil {
 		return fmt.Errorf("Branch '%s' already exists. Use a different name, or delete it first.", branchName)
 	}
 
@@ -72,11 +86,28 @@ func NewCommand(branchName string, fromBranch string, runCmd string, bg bool) er
 		return fmt.Errorf("Worktree already exists at %s", worktreePath)
 	}
 
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+
+	// --sparse overrides sparse_paths from config
+	sparseList := cfg.SparsePaths
+	if sparse != "" {
+		sparseList = strings.Split(sparse, ",")
+	}
+	sparsePaths := worktree.NormalizeSparsePaths(sparseList)
+
+	if dryRun {
+		printNewDryRun(repoRoot, worktreePath, branchName, startPoint, cfg.Profile, sparsePaths, cfg.CopyFiles, cfg.CopyIgnored)
+		return nil
+	}
+
 	// === Execution Phase ===
 
 	// Step 9: Create worktree (interrupt-safe)
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	createErr := worktree.Create(ctx, worktreePath, branchName, baseBranch)
+	createErr := worktree.Create(ctx, worktreePath, branchName, startPoint, sparsePaths)
 	stop()
 	if createErr != nil {
 		interrupted := ctx.Err() != nil
@@ -97,38 +128,87 @@ func NewCommand(branchName string, fromBranch string, runCmd string, bg bool) er
 		}
 		return fmt.Errorf("failed to create worktree: %w", createErr)
 	}
+	if cfg.Profile != "" {
+		if err := worktree.RecordProfile(worktreePath, cfg.Profile); err != nil {
+			ui.Warn(fmt.Sprintf("Could not record profile %s: %v", cfg.Profile, err))
+		}
+	}
 
-	// Step 10: Copy config files
-	if cfg.CopyFiles != "" {
-		repoRoot, err := git.GetRepoRoot()
-		if err != nil {
-			return fmt.Errorf("failed to get repo root: %w", err)
+	// Step 10: Initialize submodules and Git LFS objects (opt-in)
+	if cfg.InitSubmodules {
+		if err := worktree.InitSubmodules(repoRoot, worktreePath); err != nil {
+			// Non-fatal: the worktree is usable, submodules can be updated by hand
+			ui.Warn(fmt.Sprintf("%v (run: git submodule update --init --recursive)", err))
 		}
+	}
+	if cfg.LFSPull {
+		if err := worktree.PullLFS(worktreePath); err != nil {
+			ui.Warn(fmt.Sprintf("Could not pull LFS objects: %v", err))
+		}
+	}
 
-		if err := worktree.CopyFiles(repoRoot, worktreePath, cfg.CopyFiles); err != nil {
+	// Step 11: Copy config files (copy_files globs + selected git-ignored files)
+	if len(cfg.CopyFiles) > 0 || len(cfg.CopyIgnored) > 0 {
+		plan, err := worktree.BuildCopyPlan(repoRoot, cfg.CopyFiles, cfg.CopyIgnored)
+		if err != nil {
+			ui.Warn(fmt.Sprintf("Could not select ignored files: %v", err))
+		}
+		if err := worktree.CopyFiles(repoRoot, worktreePath, plan); err != nil {
 			// Non-fatal: log warning but continue
 			ui.Warn(fmt.Sprintf("Some files could not be copied: %v", err))
 		}
 	}
 
-	// Step 11: Run pre-session command
+	sessionName := git.GenerateSessionName(repoName, branchName)
+	hookContext := hooks.Context{
+		RepoName:     repoName,
+		RepoRoot:     repoRoot,
+		Branch:       branchName,
+		BaseBranch:   baseBranch,
+		WorktreePath: worktreePath,
+		SessionName:  sessionName,
+	}
+
+	// Step 12: Run post_create hook (aborting removes the new worktree and branch)
+	if err := runHook(cfg, hooks.PostCreate, worktreePath, hookContext); err != nil {
+		ui.Warn("Cleaning up the new worktree and branch...")
+		if cleanupErr := cleanupWorktree(worktreePath, branchName); cleanupErr != nil {
+			return fmt.Errorf("%w (worktree left at %s)", errors.Join(err, cleanupErr), worktreePath)
+		}
+		return err
+	}
+
+	// Step 13: Run pre-session command (blocking, or deferred into the tmux session)
+	setupCommand := ""
 	if cfg.PreSessionCmd != "" {
-		if err := worktree.RunPreSessionCommand(worktreePath, cfg.PreSessionCmd, cfg.SandboxTool); err != nil {
-			var preErr worktree.PreSessionError
-			if errors.As(err, &preErr) {
-				ui.Warn(fmt.Sprintf("Pre-session command failed (exit code: %d)", preErr.ExitCode))
-			} else {
-				ui.Warn(fmt.Sprintf("Pre-session command failed: %v", err))
+		switch cfg.PreSessionMode {
+		case worktree.SetupModeWindow, worktree.SetupModeAgent:
+			statusPath, err := worktree.SetupStatusPath(worktreePath)
+			if err != nil {
+				return err
 			}
-			if !promptContinue() {
-				return fmt.Errorf("Aborted due to pre-session command failure")
+			setupCommand = worktree.SetupCommandLine(cfg.PreSessionCmd, statusPath, cfg.PreSessionTimeout)
+		default:
+			if err := worktree.RunPreSessionCommand(worktreePath, cfg.PreSessionCmd, cfg.SandboxTool, cfg.PreSessionTimeout); err != nil {
+				var preErr worktree.PreSessionError
+				if errors.As(err, &preErr) {
+					if preErr.TimedOut {
+						ui.Warn(fmt.Sprintf("Pre-session command timed out after %s", cfg.PreSessionTimeout))
+					} else {
+						ui.Warn(fmt.Sprintf("Pre-session command failed (exit code: %d)", preErr.ExitCode))
+					}
+				} else {
+					ui.Warn(fmt.Sprintf("Pre-session command failed: %v", err))
+				}
+				if !promptContinue() {
+					return fmt.Errorf("Aborted due to pre-session command failure")
+				}
 			}
 		}
 	}
 
-	// Step 12: Create tmux session
+	// Step 14: Create tmux session
 	ui.Info("Creating tmux session...")
-	sessionName := git.GenerateSessionName(repoName, branchName)
 
 	// Prepare session configuration
 	sessionConfig := &tmux.SessionConfig{
@@ -137,10 +217,12 @@ func NewCommand(branchName string, fromBranch string, runCmd string, bg bool) er
 		SandboxTool:  cfg.SandboxTool,
 		RunCommand:   runCmd,
 		CustomLayout: cfg.TmuxLayout,
+		SetupCommand: setupCommand,
+		SetupInAgent: cfg.PreSessionMode == worktree.SetupModeAgent,
 	}
 
 	// Create session (custom or default layout)
-	if cfg.TmuxLayout != "" {
+	if len(cfg.TmuxLayout) > 0 {
 		// Use custom layout
 		if err := tmux.CreateCustomLayout(sessionConfig); err != nil {
 			return fmt.Errorf("failed to create tmux session: %w", err)
@@ -154,13 +236,21 @@ func NewCommand(branchName string, fromBranch string, runCmd string, bg bool) er
 
 	// Format window list for success message
 	separator := ", "
-	if cfg.TmuxLayout != "" {
+	if len(cfg.TmuxLayout) > 0 {
 		separator = " "
 	}
 	windowList := strings.Join(sessionConfig.WindowNames, separator)
 	ui.Success(fmt.Sprintf("  Created session %s (windows: %s)", ui.BoldText(sessionName), windowList))
+	if setupCommand != "" {
+		ui.Info(fmt.Sprintf("  Pre-session command running in the session; check progress with %s", ui.BoldText("mxt list")))
+	}
+
+	// Step 15: Run on_session_open hook
+	if err := runHook(cfg, hooks.OnSessionOpen, worktreePath, hookContext); err != nil {
+		return err
+	}
 
-	// Step 13: Open terminal (unless --bg)
+	// Step 16: Open terminal (unless --bg)
 	if !bg {
 		if err := terminal.Open(cfg.Terminal, sessionName, cfg.SandboxTool); err != nil {
 			ui.Warn(fmt.Sprintf("Failed to open terminal: %v", err))
@@ -168,41 +258,110 @@ func NewCommand(branchName string, fromBranch string, runCmd string, bg bool) er
 		}
 	}
 
-	// Step 14: Success message
+	// Step 17: Success message
 	fmt.Println()
 	ui.Success(fmt.Sprintf("Ready! Worktree: %s", ui.CyanText(worktreePath)))
 
 	return nil
 }
 
-// validateBranchExists checks if a git branch exists (local or remote).
-// Returns nil if branch exists, error if it doesn't.
-func validateBranchExists(branch string) error {
-	// Check local branch
-	if err := runGitCommand("show-ref", "--verify", fmt.Sprintf("refs/heads/%s", branch)); err == nil {
-		return nil
+// printNewDryRun prints what NewCommand would do for the given branch.
+func printNewDryRun(repoRoot, worktreePath, branchName, baseBranch, profile string, sparsePaths []string, copyFiles, copyIgnored []string) {
+	ui.Info("Dry run: no changes will be made")
+	fmt.Println()
+	fmt.Printf("  Branch:    %s %s\n", ui.BoldText(branchName), ui.DimText("from "+baseBranch))
+	fmt.Printf("  Path:      %s\n", ui.DimText(worktreePath))
+	if profile != "" {
+		fmt.Printf("  Profile:   %s\n", profile)
 	}
+	if len(sparsePaths) > 0 {
+		fmt.Printf("  Sparse:    %s\n", strings.Join(sparsePaths, ", "))
+	}
+	fmt.Println()
 
-	// Check remote branch
-	if err := runGitCommand("show-ref", "--verify", fmt.Sprintf("refs/remotes/origin/%s", branch)); err == nil {
-		return nil
+	if len(copyFiles) == 0 && len(copyIgnored) == 0 {
+		ui.Info("No copy_files or copy_ignored configured.")
+		return
 	}
 
-	return fmt.Errorf("branch not found")
+	plan, err := worktree.BuildCopyPlan(repoRoot, copyFiles, copyIgnored)
+	if err != nil {
+		ui.Warn(fmt.Sprintf("Could not select ignored files: %v", err))
+	}
+	for _, pattern := range plan.Invalid {
+		ui.Warn(fmt.Sprintf("  Invalid pattern: %s", ui.DimText(pattern)))
+	}
+	for _, pattern := range plan.Missing {
+		ui.Warn(fmt.Sprintf("  Not found: %s", ui.DimText(pattern)))
+	}
+	if len(plan.Files) == 0 {
+		ui.Info("No files would be copied.")
+		return
+	}
+	ui.Info(fmt.Sprintf("Files that would be copied (%d):", len(plan.Files)))
+	for _, relPath := range plan.Files {
+		fmt.Printf("  %s\n", relPath)
+	}
 }
 
-// runGitCommand executes a git command and returns an error if it fails.
-func runGitCommand(args ...string) error {
-	cmd := exec.Command("git", args...)
-	return cmd.Run()
+// resolveStartPoint returns the ref a new branch should start from.
+// With fetch set, baseBranch is fetched from remote and the fetched
+// <remote>/<baseBranch> ref is preferred; if the fetch fails the local branch is used.
+// Without fetch, the local branch is used, falling back to the remote-tracking ref.
+// In a dry run nothing is fetched and the ref that would be used is returned.
+func resolveStartPoint(baseBranch, remote string, fetch bool, dryRun bool) (string, error) {
+	remoteRef := remote + "/" + baseBranch
+
+	if fetch {
+		if dryRun {
+			return remoteRef, nil
+		}
+		ui.Info(fmt.Sprintf("Fetching %s from %s...", baseBranch, remote))
+		if err := git.Fetch(remote, baseBranch); err != nil {
+			ui.Warn(fmt.Sprintf("Fetch failed, branching from local %s: %v", baseBranch, err))
+		} else if git.RefExists("refs/remotes/" + remoteRef) {
+			return remoteRef, nil
+		}
+	}
+
+	if git.RefExists("refs/heads/" + baseBranch) {
+		return baseBranch, nil
+	}
+	if git.RefExists("refs/remotes/" + remoteRef) {
+		return remoteRef, nil
+	}
+	return "", fmt.Errorf("Base branch '%s' does not exist.", baseBranch)
+}
+
+// validateLayout parses cfg's tmux_layout, if any, for the session mxt new
+// would create: a "setup" window clashes with the one added for a
+// pre_session_cmd that runs in window mode (or agent mode without an agent
+// window).
+func validateLayout(cfg *config.Config) ([]tmux.Window, error) {
+	if len(cfg.TmuxLayout) == 0 {
+		return nil, nil
+	}
+	deferred := cfg.PreSessionCmd != "" && (cfg.PreSessionMode == worktree.SetupModeWindow || cfg.PreSessionMode == worktree.SetupModeAgent)
+	return tmux.ValidateLayout(cfg.TmuxLayout, deferred, cfg.PreSessionMode == worktree.SetupModeAgent)
+}
+
+// validateBranchExists checks if a git branch exists (local or on remote).
+// Returns nil if branch exists, error if it doesn't.
+func validateBranchExists(branch, remote string) error {
+	if git.RefExists("refs/heads/"+branch) || git.RefExists(fmt.Sprintf("refs/remotes/%s/%s", remote, branch)) {
+		return nil
+	}
+	return fmt.Errorf("branch not found")
 }
 
 // promptContinue prompts the user to continue after a failure.
 // Returns true if user enters 'y' or 'Y', false otherwise.
+// When stdin is not a terminal it does not read input and returns false.
 func promptContinue() bool {
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Print("Continue anyway? (y/N) ")
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
 	}
+	fmt.Print("Continue anyway? (y/N) ")
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
 	if err != nil && err != io.EOF {
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+// TestResolveStartPoint checks that fetch_before_new branches from the freshly fetched remote ref
+func TestResolveStartPoint(t *testing.T) {
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	clone := filepath.Join(root, "clone")
+	upstreamGit := testutil.NewRepo(t, upstream)
+	testutil.RunGit(t, root, "clone", "-q", upstream, clone)
+	// Advance upstream so the clone's origin/main is stale until fetched
+	upstreamGit("commit", "-q", "--allow-empty", "-m", "two")
+	newHead := upstreamGit("rev-parse", "HEAD")
+	t.Chdir(clone)
+
+	tests := []struct {
+		name     string
+		base     string
+		fetch    bool
+		dryRun   bool
+		expected string
+	}{
+		{name: "local branch without fetch", base: "main", expected: "main"},
+		{name: "dry run does not fetch", base: "main", fetch: true, dryRun: true, expected: "origin/main"},
+		{name: "fetched remote ref", base: "main", fetch: true, expected: "origin/main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startPoint, err := resolveStartPoint(tt.base, "origin", tt.fetch, tt.dryRun)
+			if err != nil {
+				t.Fatalf("resolveStartPoint() error = %v", err)
+			}
+			if startPoint != tt.expected {
+				t.Errorf("resolveStartPoint() = %q, want %q", startPoint, tt.expected)
+			}
+		})
+	}
+
+	if head := testutil.RunGit(t, clone, "rev-parse", "origin/main"); head != newHead {
+		t.Errorf("origin/main = %s after fetch, want %s", head, newHead)
+	}
+	if _, err := resolveStartPoint("missing", "origin", false, false); err == nil {
+		t.Error("resolveStartPoint() expected error for missing base branch")
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	layout := []string{"dev:hx", "setup:bin/setup"}
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{name: "no pre_session_cmd", cfg: config.Config{TmuxLayout: layout, PreSessionMode: "window"}},
+		{name: "block mode", cfg: config.Config{TmuxLayout: layout, PreSessionCmd: "npm install", PreSessionMode: "block"}},
+		{name: "window mode", cfg: config.Config{TmuxLayout: layout, PreSessionCmd: "npm install", PreSessionMode: "window"}, wantErr: true},
+		{name: "agent mode without agent window", cfg: config.Config{TmuxLayout: layout, PreSessionCmd: "npm install", PreSessionMode: "agent"}, wantErr: true},
+		{name: "agent mode", cfg: config.Config{TmuxLayout: append(layout, "agent:"), PreSessionCmd: "npm install", PreSessionMode: "agent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateLayout(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestNewCommandPostCreateAbortRollsBack checks an aborting post_create hook
+// leaves neither the worktree nor the branch behind.
+func TestNewCommandPostCreateAbortRollsBack(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "app")
+	worktreeDir := filepath.Join(base, "wt")
+	configDir := filepath.Join(base, "config")
+	t.Setenv("MXT_CONFIG_DIR", configDir)
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	globalConfig := fmt.Sprintf("worktree_dir = %q\nbase_branch = \"main\"\n\n[hooks]\npost_create = { command = \"exit 1\", on_failure = \"abort\" }\n", worktreeDir)
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(globalConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repoGit := testutil.NewRepo(t, repo)
+	t.Chdir(repo)
+
+	if err := NewCommand("feature", "", "", "", "", true, false); err == nil {
+		t.Fatal("NewCommand() should fail when post_create aborts")
+	}
+	if _, err := os.Stat(filepath.Join(worktreeDir, "app", "feature")); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists after the abort (stat error = %v)", err)
+	}
+	if branches := repoGit("branch", "--list", "feature"); branches != "" {
+		t.Errorf("branch still exists after the abort: %q", branches)
+	}
+}
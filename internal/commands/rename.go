@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/tmux"
+	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+// renameStep is one reversible step of a rename.
+type renameStep struct {
+	description string // e.g. "move worktree to <path>", used in errors
+	done        string // Printed when the step succeeds
+	run         func() error
+	undo        func() error
+}
+
+// RenameCommand renames a managed worktree's branch, moves the worktree to the
+// path computed for the new branch name, and renames its tmux session.
+// If any step fails, the completed steps are rolled back in reverse order.
+func RenameCommand(oldBranch, newBranch string) error {
+	// Step 1: Prerequisite checks
+	if !git.IsInsideWorkTree() {
+		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+
+	// Step 2: Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	repoName, err := git.GetRepoName()
+	if err != nil {
+		return fmt.Errorf("failed to get repository name: %w", err)
+	}
+
+	// Step 3: Validate the old worktree and the new branch name
+	if oldBranch == newBranch {
+		return fmt.Errorf("New branch name is the same as the old one.")
+	}
+	if !git.ValidBranchName(newBranch) {
+		return fmt.Errorf("Invalid branch name: '%s'", newBranch)
+	}
+	if !git.RefExists("refs/heads/" + oldBranch) {
+		return fmt.Errorf("Branch '%s' does not exist.", oldBranch)
+	}
+	if git.RefExists("refs/heads/" + newBranch) {
+		return fmt.Errorf("Branch '%s' already exists. Use a different name, or delete it first.", newBranch)
+	}
+
+	target, err := resolveWorktreeTarget(cfg.WorktreeDir, repoName, oldBranch)
+	if err != nil {
+		return err
+	}
+	oldPath := target.path
+
+	// The worktree stays under the worktree_dir of the profile it was created with
+	cfg, err = loadWorktreeConfig(target, "")
+	if err != nil {
+		return err
+	}
+	newPath := git.CalculateWorktreePath(cfg.WorktreeDir, repoName, newBranch)
+	if newPath != oldPath {
+		if _, err := os.Stat(newPath); err == nil {
+			return fmt.Errorf("Worktree already exists at %s", newPath)
+		}
+	}
+
+	oldSession := git.GenerateSessionName(repoName, oldBranch)
+	newSession := git.GenerateSessionName(repoName, newBranch)
+	sessionActive := tmux.HasSession(oldSession, cfg.SandboxTool)
+	if sessionActive && newSession != oldSession && tmux.HasSession(newSession, cfg.SandboxTool) {
+		return fmt.Errorf("tmux session %s already exists", newSession)
+	}
+
+	// Step 4: Build the reversible steps
+	steps := []renameStep{{
+		description: fmt.Sprintf("rename branch %s → %s", oldBranch, newBranch),
+		done:        "Branch renamed",
+		run:         func() error { return git.RenameBranch(oldBranch, newBranch) },
+		undo:        func() error { return git.RenameBranch(newBranch, oldBranch) },
+	}}
+	if newPath != oldPath {
+		steps = append(steps, renameStep{
+			description: fmt.Sprintf("move worktree to %s", newPath),
+			done:        "Worktree moved",
+			run:         func() error { return worktree.Move(oldPath, newPath) },
+			undo:        func() error { return worktree.Move(newPath, oldPath) },
+		})
+	}
+	if sessionActive && newSession != oldSession {
+		steps = append(steps, renameStep{
+			description: fmt.Sprintf("rename session %s → %s", oldSession, newSession),
+			done:        "Session renamed",
+			run:         func() error { return tmux.RenameSession(oldSession, newSession, cfg.SandboxTool) },
+			undo:        func() error { return tmux.RenameSession(newSession, oldSession, cfg.SandboxTool) },
+		})
+	}
+
+	// Step 5: Run the steps, rolling back on failure
+	if err := runRenameSteps(steps); err != nil {
+		return err
+	}
+
+	// Step 6: Success message
+	fmt.Println()
+	ui.Success(fmt.Sprintf("Renamed %s → %s", ui.CyanText(oldBranch), ui.CyanText(newBranch)))
+	fmt.Printf("  Path:      %s\n", ui.DimText(newPath))
+	if sessionActive {
+		fmt.Printf("  Session:   %s\n", newSession)
+		if newPath != oldPath {
+			ui.Info(fmt.Sprintf("Open shells moved with the worktree; run %s to start fresh ones", ui.BoldText("mxt sessions relaunch "+newBranch)))
+		}
+	}
+
+	return nil
+}
+
+// runRenameSteps runs steps in order. If one fails, the steps that already
+// succeeded are undone in reverse order. The returned error includes any
+// rollback failures so the user knows what to fix by hand.
+func runRenameSteps(steps []renameStep) error {
+	for i, step := range steps {
+		if err := step.run(); err != nil {
+			stepErr := fmt.Errorf("failed to %s: %w", step.description, err)
+			if i == 0 {
+				return stepErr
+			}
+
+			ui.Warn("Rename failed. Rolling back...")
+			var rollbackErrs []error
+			for j := i - 1; j >= 0; j-- {
+				if undoErr := steps[j].undo(); undoErr != nil {
+					rollbackErrs = append(rollbackErrs, fmt.Errorf("undo %s: %w", steps[j].description, undoErr))
+				}
+			}
+			if len(rollbackErrs) > 0 {
+				return fmt.Errorf("%w; rollback incomplete, fix manually: %w", stepErr, errors.Join(rollbackErrs...))
+			}
+			return stepErr
+		}
+		ui.Success(step.done)
+	}
+	return nil
+}
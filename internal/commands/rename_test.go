@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunRenameStepsRollsBackInReverseOrder(t *testing.T) {
+	var calls []string
+	step := func(name string, runErr, undoErr error) renameStep {
+		return renameStep{
+			description: name,
+			done:        name + " done",
+			run: func() error {
+				calls = append(calls, "run "+name)
+				return runErr
+			},
+			undo: func() error {
+				calls = append(calls, "undo "+name)
+				return undoErr
+			},
+		}
+	}
+
+	err := runRenameSteps([]renameStep{
+		step("branch", nil, nil),
+		step("worktree", nil, nil),
+		step("session", errors.New("no server"), nil),
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to session: no server") {
+		t.Fatalf("runRenameSteps() error = %v, want session failure", err)
+	}
+	expected := []string{"run branch", "run worktree", "run session", "undo worktree", "undo branch"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("calls = %v, want %v", calls, expected)
+	}
+}
+
+func TestRunRenameStepsReportsRollbackFailure(t *testing.T) {
+	steps := []renameStep{
+		{
+			description: "rename branch",
+			run:         func() error { return nil },
+			undo:        func() error { return errors.New("branch exists") },
+		},
+		{
+			description: "move worktree",
+			run:         func() error { return errors.New("target exists") },
+			undo:        func() error { return nil },
+		},
+	}
+
+	err := runRenameSteps(steps)
+	if err == nil {
+		t.Fatal("runRenameSteps() expected error")
+	}
+	for _, want := range []string{"failed to move worktree: target exists", "rollback incomplete", "undo rename branch: branch exists"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("runRenameSteps() error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestRunRenameStepsSuccess(t *testing.T) {
+	ran := 0
+	steps := []renameStep{
+		{run: func() error { ran++; return nil }, undo: func() error { t.Fatal("unexpected undo"); return nil }},
+		{run: func() error { ran++; return nil }, undo: func() error { t.Fatal("unexpected undo"); return nil }},
+	}
+	if err := runRenameSteps(steps); err != nil {
+		t.Fatalf("runRenameSteps() error = %v", err)
+	}
+	if ran != 2 {
+		t.Errorf("ran %d steps, want 2", ran)
+	}
+}
@@ -26,15 +26,15 @@ var runFzfSelector fzfRunner = runFzf
 func SessionUsage(action string) string {
 	switch action {
 	case "open", "launch", "start":
-		return "Usage: mxt sessions open <branch> [--run claude|codex] [--bg] (omit branch to select interactively)"
+		return "Usage: mxt sessions open <branch> [--run claude|codex] [--profile <name>] [--bg] (omit branch to select interactively)"
 	case "close", "kill", "stop":
 		return "Usage: mxt sessions close <branch> (omit branch to select interactively)"
 	case "relaunch", "restart":
-		return "Usage: mxt sessions relaunch <branch> [--run claude|codex] [--bg] (omit branch to select interactively)"
+		return "Usage: mxt sessions relaunch <branch> [--run claude|codex] [--profile <name>] [--bg] (omit branch to select interactively)"
 	case "attach":
 		return "Usage: mxt sessions attach <branch> [dev|agent] (omit branch to select interactively)"
 	default:
-		return "Usage: mxt sessions <open|close|relaunch|attach> <branch> [--run claude|codex] [--bg] (omit branch to select interactively)"
+		return "Usage: mxt sessions <open|close|relaunch|attach> <branch> [--run claude|codex] [--profile <name>] [--bg] (omit branch to select interactively)"
 	}
 }
 
@@ -102,7 +102,7 @@ func formatWorktreeSelectionItems(worktrees []WorktreeInfo) []string {
 		if wt.SessionActive {
 			status = "active"
 		}
-		items = append(items, fmt.Sprintf("%s\t(%s)", wt.BranchName, status))
+		items = append(items, fmt.Sprintf("%s\t(%s)", wt.Target(), status))
 	}
 	return items
 }
@@ -2,20 +2,22 @@ package commands
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/gkarolyi/mxt/internal/config"
 	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/hooks"
 	"github.com/gkarolyi/mxt/internal/sandbox"
 	"github.com/gkarolyi/mxt/internal/terminal"
 	"github.com/gkarolyi/mxt/internal/tmux"
 	"github.com/gkarolyi/mxt/internal/ui"
+	"github.com/gkarolyi/mxt/internal/worktree"
 )
 
 // SessionsCommand handles tmux session management for existing worktrees.
-// Supports actions: open, close, relaunch, attach
-func SessionsCommand(action string, branchName string, runCmd string, bg bool) error {
+// Supports actions: open, close, relaunch, attach. profile applies to open
+// and relaunch (default: the profile the worktree was created with).
+func SessionsCommand(action string, branchName string, runCmd string, profile string, bg bool) error {
 	// Normalize action (handle aliases)
 	switch action {
 	case "launch", "start":
@@ -29,11 +31,11 @@ func SessionsCommand(action string, branchName string, runCmd string, bg bool) e
 	// Dispatch to appropriate handler
 	switch action {
 	case "open":
-		return sessionsOpen(branchName, runCmd, bg)
+		return sessionsOpen(branchName, runCmd, profile, bg)
 	case "close":
 		return sessionsClose(branchName)
 	case "relaunch":
-		return sessionsRelaunch(branchName, runCmd, bg)
+		return sessionsRelaunch(branchName, runCmd, profile, bg)
 	case "attach":
 		return sessionsAttach(branchName, runCmd) // runCmd used as window name for attach
 	default:
@@ -42,7 +44,9 @@ func SessionsCommand(action string, branchName string, runCmd string, bg bool) e
 }
 
 // sessionsOpen creates a tmux session for an existing worktree and opens terminal.
-func sessionsOpen(branchName string, runCmd string, bg bool) error {
+// The session uses the [[rules]] matching the worktree's branch and profile,
+// else the profile recorded when the worktree was created, else default_profile.
+func sessionsOpen(branchName string, runCmd string, profile string, bg bool) error {
 	// Step 1: Require git repository
 	if !git.IsInsideWorkTree() {
 		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
@@ -79,23 +83,29 @@ func sessionsOpen(branchName string, runCmd string, bg bool) error {
 		}
 	}
 
-	worktreePath := git.CalculateWorktreePath(cfg.WorktreeDir, repoName, branchName)
+	// Step 5: Validate worktree exists (by branch, or by path for detached worktrees)
+	target, err := resolveWorktreeTarget(cfg.WorktreeDir, repoName, branchName)
+	if err != nil {
+		return err
+	}
+	worktreePath := target.path
 
-	// Step 5: Validate worktree exists
-	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-		return fmt.Errorf("Worktree not found: %s", worktreePath)
+	// Step 6: Apply the branch's rules and profile (--profile, else the one recorded by mxt new)
+	cfg, err = loadWorktreeConfig(target, profile)
+	if err != nil {
+		return err
 	}
 
-	// Step 6: Determine session name
-	sessionName := git.GenerateSessionName(repoName, branchName)
+	// Step 7: Determine session name
+	sessionName := target.sessionName
 
-	// Step 7: Check if session already exists
+	// Step 8: Check if session already exists
 	if tmux.HasSession(sessionName, cfg.SandboxTool) {
 		ui.Warn(fmt.Sprintf("Session %s already exists", sessionName))
 		return nil
 	}
 
-	// Step 8: Create tmux session
+	// Step 9: Create tmux session
 
 	sessionConfig := &tmux.SessionConfig{
 		SessionName:  sessionName,
@@ -106,7 +116,7 @@ func sessionsOpen(branchName string, runCmd string, bg bool) error {
 	}
 
 	// Create session (custom or default layout)
-	if cfg.TmuxLayout != "" {
+	if len(cfg.TmuxLayout) > 0 {
 		if err := tmux.CreateCustomLayout(sessionConfig); err != nil {
 			return fmt.Errorf("failed to create tmux session: %w", err)
 		}
@@ -118,13 +128,29 @@ func sessionsOpen(branchName string, runCmd string, bg bool) error {
 
 	// Format window list for success message
 	separator := ", "
-	if cfg.TmuxLayout != "" {
+	if len(cfg.TmuxLayout) > 0 {
 		separator = " "
 	}
 	windowList := strings.Join(sessionConfig.WindowNames, separator)
 	ui.Success(fmt.Sprintf("  Created session %s (windows: %s)", ui.BoldText(sessionName), windowList))
 
-	// Step 9: Open terminal (unless --bg)
+	// Step 10: Run on_session_open hook
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+	hookContext := hooks.Context{
+		RepoName:     repoName,
+		RepoRoot:     repoRoot,
+		Branch:       target.branch,
+		WorktreePath: worktreePath,
+		SessionName:  sessionName,
+	}
+	if err := runHook(cfg, hooks.OnSessionOpen, worktreePath, hookContext); err != nil {
+		return err
+	}
+
+	// Step 11: Open terminal (unless --bg)
 	if !bg {
 		if err := terminal.Open(cfg.Terminal, sessionName, cfg.SandboxTool); err != nil {
 			ui.Warn(fmt.Sprintf("Failed to open terminal: %v", err))
@@ -135,6 +161,36 @@ func sessionsOpen(branchName string, runCmd string, bg bool) error {
 	return nil
 }
 
+// loadWorktreeConfig returns the config for a worktree's session, with the
+// [[rules]] matching its branch applied. An explicit profile is loaded and
+// recorded, replacing the one mxt new recorded; otherwise the recorded
+// profile is loaded, falling back to default_profile if it no longer exists.
+func loadWorktreeConfig(target worktreeTarget, profile string) (*config.Config, error) {
+	if profile != "" {
+		cfg, err := config.LoadFor(target.branch, profile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if err := worktree.RecordProfile(target.path, profile); err != nil {
+			ui.Warn(fmt.Sprintf("Could not record profile %s: %v", profile, err))
+		}
+		return cfg, nil
+	}
+
+	if recorded, ok := worktree.ReadProfile(target.path); ok {
+		cfg, err := config.LoadFor(target.branch, recorded)
+		if err == nil {
+			return cfg, nil
+		}
+		ui.Warn(fmt.Sprintf("Ignoring profile %s recorded for this worktree: %v", recorded, err))
+	}
+	cfg, err := config.LoadFor(target.branch, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
 // sessionsClose kills a tmux session for a worktree.
 func sessionsClose(branchName string) error {
 	// Step 1: Require git repository
@@ -169,7 +225,7 @@ func sessionsClose(branchName string) error {
 		}
 	}
 
-	sessionName := git.GenerateSessionName(repoName, branchName)
+	sessionName := targetSessionName(cfg.WorktreeDir, repoName, branchName)
 
 	if !tmux.HasSession(sessionName, cfg.SandboxTool) {
 		return nil
@@ -185,7 +241,7 @@ func sessionsClose(branchName string) error {
 }
 
 // sessionsRelaunch kills and recreates a tmux session.
-func sessionsRelaunch(branchName string, runCmd string, bg bool) error {
+func sessionsRelaunch(branchName string, runCmd string, profile string, bg bool) error {
 	if branchName == "" {
 		// Step 1: Require git repository
 		if !git.IsInsideWorkTree() {
@@ -219,7 +275,7 @@ func sessionsRelaunch(branchName string, runCmd string, bg bool) error {
 		return err
 	}
 	// Step 5: Open the session
-	if err := sessionsOpen(branchName, runCmd, bg); err != nil {
+	if err := sessionsOpen(branchName, runCmd, profile, bg); err != nil {
 		return err
 	}
 	return nil
@@ -260,7 +316,7 @@ func sessionsAttach(branchName string, windowName string) error {
 		}
 	}
 
-	sessionName := git.GenerateSessionName(repoName, branchName)
+	sessionName := targetSessionName(cfg.WorktreeDir, repoName, branchName)
 
 	// Step 4: Check if session exists
 	if !tmux.HasSession(sessionName, cfg.SandboxTool) {
@@ -274,3 +330,13 @@ func sessionsAttach(branchName string, windowName string) error {
 
 	return nil
 }
+
+// targetSessionName returns the session name for a branch or managed worktree
+// path. If no such worktree exists (e.g. it was removed while its session kept
+// running), the name is derived from the argument as a branch name.
+func targetSessionName(worktreeDir, repoName, branchOrPath string) string {
+	if target, err := resolveWorktreeTarget(worktreeDir, repoName, branchOrPath); err == nil {
+		return target.sessionName
+	}
+	return git.GenerateSessionName(repoName, branchOrPath)
+}
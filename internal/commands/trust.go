@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/ui"
+)
+
+// trustAnswers remembers the answer for each project config for the rest of
+// the run, since a command can load the config more than once.
+var trustAnswers = map[string]bool{}
+
+var confirmTrust = promptTrustConfirm
+
+// PromptTrust asks whether to trust a project config that sets commands,
+// for config.SetTrustPrompt. Without a terminal to ask on, it warns that the
+// commands are ignored.
+func PromptTrust(path string, commands []string) bool {
+	if answer, ok := trustAnswers[path]; ok {
+		return answer
+	}
+
+	answer := false
+	if isInteractive() {
+		ui.Warn(fmt.Sprintf("%s sets commands that mxt would run:", path))
+		printTrustCommands(commands)
+		answer = confirmTrust()
+		if !answer {
+			ui.Info("Ignoring its commands. Run mxt trust to allow them.")
+		}
+	} else {
+		ui.Warn(fmt.Sprintf("Ignoring the commands in %s, which is not trusted. Run mxt trust to allow them.", path))
+	}
+	trustAnswers[path] = answer
+	return answer
+}
+
+func printTrustCommands(commands []string) {
+	for _, command := range commands {
+		fmt.Printf("    %s\n", command)
+	}
+}
+
+func promptTrustConfirm() bool {
+	fmt.Print("Trust this file? Only do so if you trust the repository. (y/N) ")
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+	response = strings.TrimSpace(response)
+	return response == "y" || response == "Y"
+}
+
+// TrustCommand trusts the current repository's .mxt.toml at its current
+// content, so the commands it sets (pre_session_cmd, tmux_layout,
+// sandbox_tool and hooks) apply. With revoke, it removes the trust instead.
+func TrustCommand(revoke bool) error {
+	// Step 1: Find the project config
+	gitRoot, err := config.FindGitRoot(".")
+	if err != nil {
+		return fmt.Errorf("Not inside a git repository. Run mxt from within your repo.")
+	}
+	path := config.GetProjectConfigPath(gitRoot)
+
+	// Step 2: Revoke
+	if revoke {
+		removed, err := config.Untrust(path)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			ui.Info(fmt.Sprintf("%s is not trusted", path))
+			return nil
+		}
+		ui.Success(fmt.Sprintf("%s is no longer trusted", path))
+		return nil
+	}
+
+	// Step 3: Show the commands being trusted
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("No project config at %s", path)
+	}
+	file, err := config.LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if commands := config.CommandSettings(file); len(commands) > 0 {
+		ui.Info(fmt.Sprintf("%s sets these commands:", path))
+		printTrustCommands(commands)
+	} else {
+		ui.Info(fmt.Sprintf("%s sets no commands", path))
+	}
+
+	// Step 4: Record the file's hash in the trust store
+	if err := config.Trust(path); err != nil {
+		return err
+	}
+	ui.Success(fmt.Sprintf("Trusted %s until it changes", path))
+	return nil
+}
+
+// keepTrust returns a function to call after mxt writes the config at path.
+// A project config that was trusted, or didn't exist, is trusted again after
+// the write, since the change came from the user.
+func keepTrust(path string, local bool) func() {
+	if !local {
+		return func() {}
+	}
+	trusted, err := config.IsTrusted(path)
+	if os.IsNotExist(err) {
+		trusted = true
+	}
+	return func() {
+		if !trusted {
+			return
+		}
+		if err := config.Trust(path); err != nil {
+			ui.Warn(fmt.Sprintf("Could not trust %s: %v", path, err))
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/config"
+)
+
+func TestKeepTrust(t *testing.T) {
+	tests := []struct {
+		name     string
+		exists   bool
+		trusted  bool
+		local    bool
+		expected bool
+	}{
+		{"trusted project config", true, true, true, true},
+		{"untrusted project config", true, false, true, false},
+		{"new project config", false, false, true, true},
+		{"global config", false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MXT_CONFIG_DIR", t.TempDir())
+			path := filepath.Join(t.TempDir(), ".mxt.toml")
+			if tt.exists {
+				if err := os.WriteFile(path, []byte("pre_session_cmd = \"make\"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.trusted {
+				if err := config.Trust(path); err != nil {
+					t.Fatalf("Trust() error = %v", err)
+				}
+			}
+
+			retrust := keepTrust(path, tt.local)
+			if err := os.WriteFile(path, []byte("pre_session_cmd = \"make deps\"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			retrust()
+
+			trusted, err := config.IsTrusted(path)
+			if err != nil {
+				t.Fatalf("IsTrusted() error = %v", err)
+			}
+			if trusted != tt.expected {
+				t.Errorf("IsTrusted() after write = %v, want %v", trusted, tt.expected)
+			}
+		})
+	}
+}
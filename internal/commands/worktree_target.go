@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gkarolyi/mxt/internal/config"
+	"github.com/gkarolyi/mxt/internal/git"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+// worktreeTarget is a managed worktree named on the command line.
+type worktreeTarget struct {
+	path        string
+	branch      string // Empty for a detached HEAD
+	sessionName string
+}
+
+// resolveWorktreeTarget finds the managed worktree that target names: a
+// branch (the worktree at its computed path, or one checked out under the
+// worktree_dir of its own profile), or the path of a managed worktree, which
+// is how detached worktrees are addressed.
+func resolveWorktreeTarget(worktreeDir, repoName, target string) (worktreeTarget, error) {
+	branchPath := git.CalculateWorktreePath(worktreeDir, repoName, target)
+	if _, err := os.Stat(branchPath); err == nil {
+		branch := target
+		if git.GetCurrentBranch(branchPath) == "" {
+			branch = "" // Detached: there is no branch to act on
+		}
+		return newWorktreeTarget(repoName, branchPath, branch), nil
+	}
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return worktreeTarget{}, err
+	}
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		for _, wt := range worktrees {
+			if samePath(wt.Path, target) {
+				if !isManagedWorktree(worktreeDir, repoName, wt) {
+					return worktreeTarget{}, fmt.Errorf("%s is not managed by mxt. Use mxt adopt to bring it under management.", wt.Path)
+				}
+				return newWorktreeTarget(repoName, wt.Path, wt.Branch), nil
+			}
+		}
+	}
+	for i, wt := range worktrees {
+		if i > 0 && wt.Branch == target && isManagedWorktree(worktreeDir, repoName, wt) {
+			return newWorktreeTarget(repoName, wt.Path, wt.Branch), nil
+		}
+	}
+
+	return worktreeTarget{}, fmt.Errorf("Worktree not found: %s", branchPath)
+}
+
+// isManagedWorktree reports whether wt lives under <worktreeDir>/<repo>/, or
+// under the worktree_dir its own config sets: its branch's rules and the
+// profile mxt new recorded for it may have put it elsewhere.
+func isManagedWorktree(worktreeDir, repoName string, wt git.Worktree) bool {
+	path := canonicalPath(wt.Path)
+	if isWithinDir(path, canonicalPath(filepath.Join(worktreeDir, repoName))) {
+		return true
+	}
+	profile, _ := worktree.ReadProfile(wt.Path)
+	cfg, err := config.LoadFor(wt.Branch, profile)
+	if err != nil || cfg.WorktreeDir == worktreeDir {
+		return false
+	}
+	return isWithinDir(path, canonicalPath(filepath.Join(cfg.WorktreeDir, repoName)))
+}
+
+func newWorktreeTarget(repoName, path, branch string) worktreeTarget {
+	return worktreeTarget{path: path, branch: branch, sessionName: worktreeSessionName(repoName, path)}
+}
+
+// worktreeSessionName returns the tmux session name for a managed worktree.
+// The directory name is the sanitized branch name, so this matches
+// git.GenerateSessionName(repoName, branch) and also works for detached
+// worktrees.
+func worktreeSessionName(repoName, path string) string {
+	return git.GenerateSessionName(repoName, filepath.Base(path))
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+	"github.com/gkarolyi/mxt/internal/worktree"
+)
+
+func TestResolveWorktreeTarget(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "app")
+	worktreeDir := filepath.Join(base, "wt")
+	featurePath := filepath.Join(worktreeDir, "app", "feature-auth")
+	scratchPath := filepath.Join(worktreeDir, "app", "scratch")
+	outsidePath := filepath.Join(base, "app-hotfix")
+	backendPath := filepath.Join(base, "wt-backend", "app", "api")
+	configDir := filepath.Join(base, "config")
+	t.Setenv("MXT_CONFIG_DIR", configDir)
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	globalConfig := fmt.Sprintf("[profiles.backend]\nworktree_dir = %q\n", filepath.Join(base, "wt-backend"))
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(globalConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repoGit := testutil.NewRepo(t, repo)
+	repoGit("worktree", "add", "-q", "-b", "feature/auth", featurePath)
+	repoGit("worktree", "add", "-q", "--detach", scratchPath)
+	repoGit("worktree", "add", "-q", "-b", "hotfix", outsidePath)
+	repoGit("worktree", "add", "-q", "-b", "api", backendPath)
+	if err := worktree.RecordProfile(backendPath, "backend"); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+
+	tests := []struct {
+		name        string
+		target      string
+		path        string
+		branch      string
+		sessionName string
+		expectErr   bool
+	}{
+		{"branch", "feature/auth", featurePath, "feature/auth", "app_feature-auth", false},
+		{"path of branch worktree", featurePath, featurePath, "feature/auth", "app_feature-auth", false},
+		{"detached by path", scratchPath, scratchPath, "", "app_scratch", false},
+		{"detached by directory name", "scratch", scratchPath, "", "app_scratch", false},
+		{"unmanaged worktree", outsidePath, "", "", "", true},
+		{"under its profile's worktree_dir", "api", backendPath, "api", "app_api", false},
+		{"path under its profile's worktree_dir", backendPath, backendPath, "api", "app_api", false},
+		{"missing", "nope", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := resolveWorktreeTarget(worktreeDir, "app", tt.target)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("resolveWorktreeTarget(%q) error = %v, expectErr %v", tt.target, err, tt.expectErr)
+			}
+			if tt.expectErr {
+				return
+			}
+			if !samePath(target.path, tt.path) || target.branch != tt.branch || target.sessionName != tt.sessionName {
+				t.Errorf("resolveWorktreeTarget(%q) = %+v, want path %q, branch %q, session %q", tt.target, target, tt.path, tt.branch, tt.sessionName)
+			}
+		})
+	}
+}
@@ -5,19 +5,224 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
 // Config represents the mxt configuration
 type Config struct {
-	WorktreeDir   string
-	Terminal      string
-	SandboxTool   string
-	CopyFiles     string
-	PreSessionCmd string
-	TmuxLayout    string
+	WorktreeDir       string
+	Terminal          string
+	SandboxTool       string
+	CopyFiles         []string // copy_files patterns, in order
+	CopyIgnored       []string // copy_ignored patterns, in order
+	PreSessionCmd     string
+	PreSessionMode    string        // block | window | agent
+	PreSessionTimeout time.Duration // Zero means no timeout
+	TmuxLayout        []string      // One window spec per entry; empty means the default layout
+	FetchBeforeNew    bool          // Fetch the base branch and branch from the remote ref
+	Remote            string        // Remote used for fetching and default-branch detection
+	BaseBranch        string        // Empty means detect from <remote>/HEAD
+	SparsePaths       []string      // Cone-mode directories; empty means full checkout
+	InitSubmodules    bool          // Initialize submodules in new worktrees
+	LFSPull           bool          // Run git lfs pull in new worktrees
+	Hooks             map[string]HookConfig
+	Profile           string // Profile applied on top of the config files; empty if none
+}
+
+// File holds the settings of one config layer: the defaults, the global
+// config or a project config. Keys the layer doesn't set are nil, so
+// MergeConfigs can tell an unset key from an explicit empty value such as
+// copy_files = [].
+type File struct {
+	WorktreeDir       *string
+	Terminal          *string
+	SandboxTool       *string
+	CopyFiles         []string
+	CopyIgnored       []string
+	PreSessionCmd     *string
+	PreSessionMode    *string
+	PreSessionTimeout *string
+	TmuxLayout        []string
+	FetchBeforeNew    *bool
+	Remote            *string
+	BaseBranch        *string
+	SparsePaths       []string
+	InitSubmodules    *bool
+	LFSPull           *bool
+	Hooks             map[string]HookConfig // By stage
+	DefaultProfile    *string
+	Profiles          map[string]*File // [profiles.<name>] tables, by name
+	Rules             []Rule           // [[rules]] tables, in file order
+	Include           []string         // include paths, as written
+	ReplaceLists      []string         // appendListKeys written as { replace = [...] }
+	Included          []string         // Files merged in by include, in load order (set by LoadConfigFile)
+}
+
+// Rule is a [[rules]] table: config keys that apply to branches matching the
+// Branch glob, as in path.Match ("hotfix/*", "spike-*").
+type Rule struct {
+	Branch string
+	File   *File
+}
+
+// matches reports whether the rule applies to branch.
+func (r Rule) matches(branch string) bool {
+	ok, _ := path.Match(r.Branch, branch)
+	return ok
+}
+
+// HookConfig is a lifecycle hook command and its failure policy.
+type HookConfig struct {
+	Command   string
+	OnFailure string // abort | warn | prompt; empty means the stage default
+}
+
+// policyOnly reports whether the hook only sets on_failure, leaving the
+// command to a lower layer.
+func (h HookConfig) policyOnly() bool {
+	return h.Command == "" && h.OnFailure != ""
+}
+
+// PreSessionModes lists the accepted pre_session_mode values.
+var PreSessionModes = []string{"block", "window", "agent"}
+
+// HookStages lists the lifecycle stages that accept a hook under [hooks].
+var HookStages = []string{"post_create", "pre_delete", "post_delete", "on_session_open"}
+
+// hookFailurePolicies lists the accepted on_failure values for hooks.
+var hookFailurePolicies = []string{"abort", "warn", "prompt"}
+
+// stringKeys, boolKeys and listKeys list the top-level keys by value type.
+var (
+	stringKeys = []string{"worktree_dir", "terminal", "sandbox_tool", "pre_session_cmd", "pre_session_mode", "pre_session_timeout", "remote", "base_branch", "default_profile"}
+	boolKeys   = []string{"fetch_before_new", "init_submodules", "lfs_pull"}
+	listKeys   = []string{"copy_files", "copy_ignored", "tmux_layout", "sparse_paths"}
+)
+
+// appendListKeys are the list keys whose entries add to those of lower
+// layers, so a project can copy one more file without repeating the global
+// list. Written as { replace = [...] }, they replace them instead. The other
+// list keys describe one whole layout or checkout and always replace.
+var appendListKeys = []string{"copy_files", "copy_ignored"}
+
+// replaces reports whether the layer's value for an append list key
+// replaces lower layers' entries rather than adding to them.
+func (f *File) replaces(key string) bool {
+	return contains(f.ReplaceLists, key)
+}
+
+// HookKey returns the flattened key for a hook stage's command, as used in
+// messages and by ValidateConfigValue.
+func HookKey(stage string) string {
+	return "hooks." + stage
+}
+
+// HookPolicyKey returns the flattened key for a hook stage's failure policy.
+func HookPolicyKey(stage string) string {
+	return "hooks." + stage + ".on_failure"
+}
+
+// stringField returns the field holding a string key, or nil if key isn't one.
+func (f *File) stringField(key string) **string {
+	switch key {
+	case "worktree_dir":
+		return &f.WorktreeDir
+	case "terminal":
+		return &f.Terminal
+	case "sandbox_tool":
+		return &f.SandboxTool
+	case "pre_session_cmd":
+		return &f.PreSessionCmd
+	case "pre_session_mode":
+		return &f.PreSessionMode
+	case "pre_session_timeout":
+		return &f.PreSessionTimeout
+	case "remote":
+		return &f.Remote
+	case "base_branch":
+		return &f.BaseBranch
+	case "default_profile":
+		return &f.DefaultProfile
+	}
+	return nil
+}
+
+// boolField returns the field holding a boolean key, or nil if key isn't one.
+func (f *File) boolField(key string) **bool {
+	switch key {
+	case "fetch_before_new":
+		return &f.FetchBeforeNew
+	case "init_submodules":
+		return &f.InitSubmodules
+	case "lfs_pull":
+		return &f.LFSPull
+	}
+	return nil
+}
+
+// listField returns the field holding a list key, or nil if key isn't one.
+func (f *File) listField(key string) *[]string {
+	switch key {
+	case "copy_files":
+		return &f.CopyFiles
+	case "copy_ignored":
+		return &f.CopyIgnored
+	case "tmux_layout":
+		return &f.TmuxLayout
+	case "sparse_paths":
+		return &f.SparsePaths
+	}
+	return nil
+}
+
+// each calls fn with every string value the layer sets: scalars, each list
+// entry, and hook commands and policies under their flattened keys.
+func (f *File) each(fn func(key, value string) error) error {
+	for _, key := range stringKeys {
+		if value := *f.stringField(key); value != nil {
+			if err := fn(key, *value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range listKeys {
+		for _, value := range *f.listField(key) {
+			if err := fn(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, stage := range HookStages {
+		hook, ok := f.Hooks[stage]
+		if !ok {
+			continue
+		}
+		if err := fn(HookKey(stage), hook.Command); err != nil {
+			return err
+		}
+		if hook.OnFailure != "" {
+			if err := fn(HookPolicyKey(stage), hook.OnFailure); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(f.Profiles) {
+		if err := f.Profiles[name].each(fn); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+	for _, rule := range f.Rules {
+		if err := rule.File.each(fn); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Branch, err)
+		}
+	}
+	return nil
 }
 
 // Load loads the configuration from defaults, global config, and project config.
@@ -29,36 +234,86 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to get working directory: %w", err)
 	}
 
-	// Load raw config map
-	configMap, err := LoadConfig(workDir)
+	return LoadConfig(workDir)
+}
+
+// LoadFor is Load for a branch, with the [[rules]] matching it and the named
+// profile applied (see LoadConfigFor).
+func LoadFor(branch, profile string) (*Config, error) {
+	workDir, err := os.Getwd()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
 	}
 
-	// Convert map to struct
+	return LoadConfigFor(workDir, branch, profile)
+}
+
+// resolve converts merged config layers into a Config, expanding the tilde
+// in worktree_dir and parsing pre_session_timeout.
+func resolve(file *File) (*Config, error) {
 	cfg := &Config{
-		WorktreeDir:   configMap["worktree_dir"],
-		Terminal:      configMap["terminal"],
-		SandboxTool:   configMap["sandbox_tool"],
-		CopyFiles:     configMap["copy_files"],
-		PreSessionCmd: configMap["pre_session_cmd"],
-		TmuxLayout:    configMap["tmux_layout"],
+		WorktreeDir:    ExpandTilde(stringValue(file.WorktreeDir)),
+		Terminal:       stringValue(file.Terminal),
+		SandboxTool:    stringValue(file.SandboxTool),
+		CopyFiles:      file.CopyFiles,
+		CopyIgnored:    file.CopyIgnored,
+		PreSessionCmd:  stringValue(file.PreSessionCmd),
+		PreSessionMode: stringValue(file.PreSessionMode),
+		TmuxLayout:     file.TmuxLayout,
+		FetchBeforeNew: boolValue(file.FetchBeforeNew),
+		Remote:         stringValue(file.Remote),
+		BaseBranch:     stringValue(file.BaseBranch),
+		SparsePaths:    file.SparsePaths,
+		InitSubmodules: boolValue(file.InitSubmodules),
+		LFSPull:        boolValue(file.LFSPull),
+		Hooks:          make(map[string]HookConfig),
+	}
+	if timeout := stringValue(file.PreSessionTimeout); timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pre_session_timeout %q: %w", timeout, err)
+		}
+		cfg.PreSessionTimeout = parsed
+	}
+	for stage, hook := range file.Hooks {
+		if hook.Command != "" {
+			cfg.Hooks[stage] = hook
+		}
 	}
 
 	return cfg, nil
 }
 
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func boolValue(value *bool) bool {
+	return value != nil && *value
+}
+
 // ParseConfig parses a config file in TOML format.
 // It supports standard TOML comments and validates keys.
-// Returns a map of config key-value pairs.
-func ParseConfig(r io.Reader) (map[string]string, error) {
+// Returns the settings the file sets; other keys are left nil.
+func ParseConfig(r io.Reader) (*File, error) {
 	decoder := toml.NewDecoder(r)
 	raw := map[string]any{}
 	if err := decoder.Decode(&raw); err != nil {
 		return nil, err
 	}
+	return decodeFile(raw)
+}
 
-	config := make(map[string]string)
+// decodeFile converts a decoded TOML document into a File, checking each
+// key's type and value. It walks the generic document rather than letting
+// go-toml fill the struct because several keys take more than one TOML type
+// (a string or an array for lists, a string or a table for hooks, an array
+// or { replace = [...] } for copy_files), and unknown keys get a suggestion.
+func decodeFile(raw map[string]any) (*File, error) {
+	file := &File{}
 	for key, value := range raw {
 		switch key {
 		case "worktree_dir", "terminal", "pre_session_cmd", "sandbox_tool":
@@ -66,24 +321,280 @@ func ParseConfig(r io.Reader) (map[string]string, error) {
 			if err != nil {
 				return nil, err
 			}
-			config[key] = parsed
-		case "copy_files":
-			parsed, err := parseStringOrArrayValue(key, value, ",")
+			*file.stringField(key) = &parsed
+		case "copy_files", "copy_ignored":
+			if table, ok := value.(map[string]any); ok {
+				replacement, ok := table["replace"]
+				if !ok || len(table) != 1 {
+					return nil, fmt.Errorf("config key %q must be an array, a string or { replace = [...] }", key)
+				}
+				value = replacement
+				file.ReplaceLists = append(file.ReplaceLists, key)
+				sort.Strings(file.ReplaceLists)
+			}
+			parsed, err := parseListValue(key, value, splitCommaList)
+			if err != nil {
+				return nil, err
+			}
+			*file.listField(key) = parsed
+		case "sparse_paths":
+			parsed, err := parseListValue(key, value, splitCommaList)
 			if err != nil {
 				return nil, err
 			}
-			config[key] = parsed
+			*file.listField(key) = parsed
 		case "tmux_layout":
-			parsed, err := parseStringOrArrayValue(key, value, " ")
+			parsed, err := parseListValue(key, value, splitTmuxLayout)
+			if err != nil {
+				return nil, err
+			}
+			file.TmuxLayout = parsed
+		case "pre_session_mode":
+			parsed, err := parseStringValue(key, value)
+			if err != nil {
+				return nil, err
+			}
+			if !contains(PreSessionModes, parsed) {
+				return nil, fmt.Errorf("invalid pre_session_mode %q (use %s)", parsed, strings.Join(PreSessionModes, ", "))
+			}
+			file.PreSessionMode = &parsed
+		case "pre_session_timeout":
+			parsed, err := parseStringValue(key, value)
+			if err != nil {
+				return nil, err
+			}
+			if parsed != "" {
+				if _, err := time.ParseDuration(parsed); err != nil {
+					return nil, fmt.Errorf("invalid pre_session_timeout %q (use a duration like 90s or 10m)", parsed)
+				}
+			}
+			file.PreSessionTimeout = &parsed
+		case "fetch_before_new", "init_submodules", "lfs_pull":
+			parsed, err := parseBoolValue(key, value)
+			if err != nil {
+				return nil, err
+			}
+			*file.boolField(key) = &parsed
+		case "remote", "base_branch":
+			parsed, err := parseStringValue(key, value)
 			if err != nil {
 				return nil, err
 			}
-			config[key] = normalizeTmuxLayout(parsed)
+			if strings.HasPrefix(parsed, "-") || strings.ContainsAny(parsed, " \t\n") {
+				return nil, fmt.Errorf("invalid %s %q", key, parsed)
+			}
+			*file.stringField(key) = &parsed
+		case "hooks":
+			parsed, err := parseHooksValue(value)
+			if err != nil {
+				return nil, err
+			}
+			file.Hooks = parsed
+		case "default_profile":
+			parsed, err := parseStringValue(key, value)
+			if err != nil {
+				return nil, err
+			}
+			if !validProfileName(parsed) {
+				return nil, fmt.Errorf("invalid default_profile %q (use letters, digits, - and _)", parsed)
+			}
+			file.DefaultProfile = &parsed
+		case "profiles":
+			parsed, err := parseProfilesValue(value)
+			if err != nil {
+				return nil, err
+			}
+			file.Profiles = parsed
+		case "rules":
+			parsed, err := parseRulesValue(value)
+			if err != nil {
+				return nil, err
+			}
+			file.Rules = parsed
+		case "include":
+			parsed, err := parseListValue(key, value, splitCommaList)
+			if err != nil {
+				return nil, err
+			}
+			file.Include = parsed
 		default:
-			return nil, fmt.Errorf("unknown config key %q", key)
+			return nil, unknownKeyError(key)
+		}
+	}
+	return file, nil
+}
+
+// parseHooksValue reads the [hooks] table.
+// Each stage accepts either a command string or a table with command and on_failure:
+//
+//	[hooks]
+//	post_create = "bin/setup-db"
+//	pre_delete = { command = "bin/dump-db", on_failure = "abort" }
+func parseHooksValue(value any) (map[string]HookConfig, error) {
+	table, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config key %q must be a table", "hooks")
+	}
+	hooks := make(map[string]HookConfig, len(table))
+	for stage, hookValue := range table {
+		if !contains(HookStages, stage) {
+			if suggestion := suggest(stage, HookStages); suggestion != "" {
+				return nil, fmt.Errorf("unknown hook %q (did you mean %q?)", stage, suggestion)
+			}
+			return nil, fmt.Errorf("unknown hook %q (use %s)", stage, strings.Join(HookStages, ", "))
+		}
+		key := HookKey(stage)
+		if command, ok := hookValue.(string); ok {
+			hooks[stage] = HookConfig{Command: command}
+			continue
+		}
+		hookTable, ok := hookValue.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("hook %q must be a string or a table", stage)
+		}
+		var hook HookConfig
+		for field, fieldValue := range hookTable {
+			switch field {
+			case "command":
+				command, err := parseStringValue(key+".command", fieldValue)
+				if err != nil {
+					return nil, err
+				}
+				hook.Command = command
+			case "on_failure":
+				policy, err := parseStringValue(HookPolicyKey(stage), fieldValue)
+				if err != nil {
+					return nil, err
+				}
+				if !contains(hookFailurePolicies, policy) {
+					return nil, fmt.Errorf("invalid on_failure %q for hook %q (use %s)", policy, stage, strings.Join(hookFailurePolicies, ", "))
+				}
+				hook.OnFailure = policy
+			default:
+				return nil, fmt.Errorf("unknown field %q in hook %q", field, stage)
+			}
+		}
+		hooks[stage] = hook
+	}
+	return hooks, nil
+}
+
+// parseProfilesValue parses the [profiles] table. Each profile is a table
+// of config keys, decoded like a config file:
+//
+//	[profiles.backend]
+//	pre_session_cmd = "bundle install"
+//	tmux_layout = ["dev:hx|lazygit", "server:bin/dev", "agent:"]
+func parseProfilesValue(value any) (map[string]*File, error) {
+	table, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config key %q must be a table", "profiles")
+	}
+	profiles := make(map[string]*File, len(table))
+	for name, profileValue := range table {
+		if !validProfileName(name) {
+			return nil, fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", name)
+		}
+		profileTable, ok := profileValue.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("profile %q must be a table", name)
+		}
+		profile, err := decodeFile(profileTable)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		if profile.DefaultProfile != nil || profile.Profiles != nil || profile.Rules != nil || profile.Include != nil {
+			return nil, fmt.Errorf("profile %q can't set default_profile, profiles, rules or include", name)
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// parseRulesValue parses the [[rules]] array of tables. Each rule has a
+// branch glob, and any other keys are decoded like a config file:
+//
+//	[[rules]]
+//	branch = "hotfix/*"
+//	base_branch = "release"
+func parseRulesValue(value any) ([]Rule, error) {
+	var tables []map[string]any
+	switch value := value.(type) {
+	case []map[string]any:
+		tables = value
+	case []any:
+		for _, entry := range value {
+			table, ok := entry.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("config key %q must be an array of tables ([[rules]])", "rules")
+			}
+			tables = append(tables, table)
+		}
+	default:
+		return nil, fmt.Errorf("config key %q must be an array of tables ([[rules]])", "rules")
+	}
+
+	rules := make([]Rule, 0, len(tables))
+	for i, table := range tables {
+		branch, ok := table["branch"].(string)
+		if !ok || branch == "" {
+			return nil, fmt.Errorf("rule %d: branch must be a non-empty glob", i+1)
+		}
+		if _, err := path.Match(branch, ""); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid branch glob: %w", branch, err)
+		}
+		settings := make(map[string]any, len(table)-1)
+		for key, setting := range table {
+			if key != "branch" {
+				settings[key] = setting
+			}
+		}
+		file, err := decodeFile(settings)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", branch, err)
+		}
+		if file.Profiles != nil || file.Rules != nil || file.Include != nil {
+			return nil, fmt.Errorf("rule %q can't set profiles, rules or include", branch)
+		}
+		rules = append(rules, Rule{Branch: branch, File: file})
+	}
+	return rules, nil
+}
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func validProfileName(name string) bool {
+	return profileNamePattern.MatchString(name)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendNew returns base followed by the entries of extra it doesn't already
+// contain, without modifying base.
+func appendNew(base, extra []string) []string {
+	result := append([]string{}, base...)
+	for _, value := range extra {
+		if !contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
 		}
 	}
-	return config, nil
+	return false
 }
 
 func parseStringValue(key string, value any) (string, error) {
@@ -94,140 +605,63 @@ func parseStringValue(key string, value any) (string, error) {
 	return parsed, nil
 }
 
-func parseStringOrArrayValue(key string, value any, joiner string) (string, error) {
-	if parsed, ok := value.(string); ok {
-		return parsed, nil
+// parseBoolValue accepts a TOML boolean (or the strings "true"/"false").
+func parseBoolValue(key string, value any) (bool, error) {
+	switch typed := value.(type) {
+	case bool:
+		return typed, nil
+	case string:
+		if typed == "true" || typed == "false" {
+			return typed == "true", nil
+		}
 	}
+	return false, fmt.Errorf("config key %q must be true or false", key)
+}
+
+// parseListValue accepts a TOML array of strings, kept entry for entry, or a
+// single string that split breaks into entries. The result is never nil, so
+// an empty value still overrides lower layers.
+func parseListValue(key string, value any, split func(string) []string) ([]string, error) {
 	switch typed := value.(type) {
-	case []string:
-		return strings.Join(typed, joiner), nil
+	case string:
+		return append([]string{}, split(typed)...), nil
 	case []any:
 		result := make([]string, 0, len(typed))
 		for _, item := range typed {
 			str, ok := item.(string)
 			if !ok {
-				return "", fmt.Errorf("config key %q array values must be strings", key)
+				return nil, fmt.Errorf("config key %q array values must be strings", key)
 			}
 			result = append(result, str)
 		}
-		return strings.Join(result, joiner), nil
+		return result, nil
 	default:
-		return "", fmt.Errorf("config key %q must be a string or array of strings", key)
+		return nil, fmt.Errorf("config key %q must be a string or array of strings", key)
 	}
 }
 
-// normalizeTmuxLayout normalizes separators in tmux_layout values.
-// It converts commas to semicolons and handles space-separated window definitions.
-// Window definitions are in format: window_name:command|command
-// Multiple windows can be separated by commas, semicolons, or newlines (spaces in multi-line)
-func normalizeTmuxLayout(layout string) string {
-	layout = strings.ReplaceAll(layout, "\n", " ")
-	layout = strings.ReplaceAll(layout, "\r", " ")
-	// First, replace commas with semicolons
-	layout = strings.ReplaceAll(layout, ",", ";")
-
-	// If already contains semicolons, we're mostly done
-	if strings.Contains(layout, ";") {
-		// Clean up double semicolons
-		for strings.Contains(layout, ";;") {
-			layout = strings.ReplaceAll(layout, ";;", ";")
-		}
-		// Trim leading/trailing semicolons
-		layout = strings.Trim(layout, ";")
-		return layout
-	}
-
-	// No semicolons yet - this means it's space-separated (from multi-line array)
-	// We need to detect window boundaries by looking for window_name: pattern
-	// A window starts with word(s) followed by colon
-
-	// Strategy: look for pattern where we see "word:" that starts a window
-	// We'll scan character by character to find word: patterns
+// splitCommaList splits the string form of a list key on commas.
+// Use the array form for entries that contain a comma.
+func splitCommaList(value string) []string {
 	var result []string
-	var currentWindow strings.Builder
-
-	runes := []rune(layout)
-	i := 0
-
-	for i < len(runes) {
-		// Skip leading whitespace
-		for i < len(runes) && (runes[i] == ' ' || runes[i] == '\t') {
-			i++
-		}
-
-		if i >= len(runes) {
-			break
-		}
-
-		// Check if this looks like start of window (word followed by :)
-		// Look ahead to find the next colon
-		colonPos := -1
-		for j := i; j < len(runes); j++ {
-			if runes[j] == ':' {
-				colonPos = j
-				break
-			}
-			if runes[j] == ' ' || runes[j] == '\t' {
-				// Space before colon, not a window name
-				break
-			}
-		}
-
-		// If we found a colon and we already have content, start new window
-		if colonPos != -1 && currentWindow.Len() > 0 {
-			result = append(result, strings.TrimSpace(currentWindow.String()))
-			currentWindow.Reset()
-		}
-
-		// Read until we find the next potential window start or end
-		if colonPos != -1 {
-			// Read through this window definition
-			// Find the end - either next window name pattern or end of string
-			windowEnd := len(runes)
-
-			// Look ahead for next window pattern (space followed by word:)
-			for j := colonPos + 1; j < len(runes)-1; j++ {
-				if runes[j] == ' ' {
-					// Check if what follows looks like window_name:
-					k := j + 1
-					for k < len(runes) && runes[k] == ' ' {
-						k++
-					}
-					if k < len(runes) {
-						// Look for : after the word
-						hasColon := false
-						for m := k; m < len(runes) && runes[m] != ' '; m++ {
-							if runes[m] == ':' {
-								hasColon = true
-								windowEnd = j
-								break
-							}
-						}
-						if hasColon {
-							break
-						}
-					}
-				}
-			}
-
-			currentWindow.WriteString(string(runes[i:windowEnd]))
-			i = windowEnd
-		} else {
-			// No colon found, just read to end
-			currentWindow.WriteString(string(runes[i:]))
-			break
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
 		}
 	}
+	return result
+}
 
-	// Don't forget the last window
-	if currentWindow.Len() > 0 {
-		result = append(result, strings.TrimSpace(currentWindow.String()))
-	}
-
-	// Join with semicolons
-	if len(result) > 0 {
-		layout = strings.Join(result, ";")
+// splitTmuxLayout splits the string form of tmux_layout into window specs.
+// Windows are separated by ',', ';' or newlines.
+func splitTmuxLayout(value string) []string {
+	var windows []string
+	for _, spec := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n' || r == '\r'
+	}) {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			windows = append(windows, spec)
+		}
 	}
-
-	return layout
+	return windows
 }
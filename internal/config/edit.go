@@ -0,0 +1,593 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Keys returns every key accepted by mxt config get/set/unset, hooks as
+// hooks.<stage> and hooks.<stage>.on_failure.
+func Keys() []string {
+	keys := append(append(append([]string{}, stringKeys...), boolKeys...), listKeys...)
+	for _, stage := range HookStages {
+		keys = append(keys, HookKey(stage), HookPolicyKey(stage))
+	}
+	return keys
+}
+
+// hookKeyStage splits a flattened hook key into its stage and whether it
+// names the on_failure policy.
+func hookKeyStage(key string) (stage string, policy bool, ok bool) {
+	for _, stage := range HookStages {
+		switch key {
+		case HookKey(stage):
+			return stage, false, true
+		case HookPolicyKey(stage):
+			return stage, true, true
+		}
+	}
+	return "", false, false
+}
+
+// CheckKey returns an error if key isn't a config key.
+func CheckKey(key string) error {
+	if contains(Keys(), key) {
+		return nil
+	}
+	return unknownKeyError(key)
+}
+
+// Lookup returns the value a layer sets for key as strings: one entry for
+// scalars, one per entry for lists. ok is false when the layer doesn't set it.
+func (f *File) Lookup(key string) (values []string, ok bool) {
+	if field := f.stringField(key); field != nil {
+		if *field == nil {
+			return nil, false
+		}
+		return []string{**field}, true
+	}
+	if field := f.boolField(key); field != nil {
+		if *field == nil {
+			return nil, false
+		}
+		return []string{strconv.FormatBool(**field)}, true
+	}
+	if field := f.listField(key); field != nil {
+		if *field == nil {
+			return nil, false
+		}
+		return *field, true
+	}
+	if stage, policy, isHook := hookKeyStage(key); isHook {
+		hook, set := f.Hooks[stage]
+		switch {
+		case !set:
+			return nil, false
+		case policy && hook.OnFailure == "":
+			return nil, false
+		case policy:
+			return []string{hook.OnFailure}, true
+		default:
+			return []string{hook.Command}, true
+		}
+	}
+	return nil, false
+}
+
+// ReadConfigFile loads the config file at path for editing, returning its
+// contents and parsed settings. A missing file reads as empty.
+func ReadConfigFile(path string) (string, *File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", &File{}, nil
+		}
+		return "", nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	file, err := ParseConfig(strings.NewReader(string(data)))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return string(data), file, nil
+}
+
+// SetConfigValue sets key in the config file at path, creating the file if
+// needed. Comments, ordering and other keys are left untouched. List keys
+// take a comma-separated string or a TOML array such as [".env", "a,b.txt"].
+func SetConfigValue(path, key, value string) error {
+	content, _, err := ReadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	updated, err := setValue(content, key, value)
+	if err != nil {
+		return err
+	}
+	return writeConfigFile(path, updated)
+}
+
+// UnsetConfigValue removes key from the config file at path. It reports
+// whether the key was set.
+func UnsetConfigValue(path, key string) (bool, error) {
+	content, _, err := ReadConfigFile(path)
+	if err != nil {
+		return false, err
+	}
+	updated, removed, err := unsetValue(content, key)
+	if err != nil || !removed {
+		return false, err
+	}
+	return true, writeConfigFile(path, updated)
+}
+
+func writeConfigFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// parseSetting checks a command-line value for key the same way a config
+// file value is checked, returning it as a single-key layer.
+func parseSetting(key, value string) (*File, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if stage, policy, ok := hookKeyStage(key); ok {
+		hook := map[string]any{"command": value}
+		if policy {
+			hook = map[string]any{"command": "", "on_failure": value}
+		}
+		raw = map[string]any{"hooks": map[string]any{stage: hook}}
+	} else if isTomlListValue(key, value) {
+		var doc map[string]any
+		if err := toml.Unmarshal([]byte("value = "+value), &doc); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		raw = map[string]any{key: doc["value"]}
+	} else {
+		raw = map[string]any{key: value}
+	}
+	file, err := decodeFile(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateConfig(file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// isTomlListValue reports whether value is written as a TOML array, or as
+// { replace = [...] } for an append list key, rather than a comma-separated
+// string.
+func isTomlListValue(key, value string) bool {
+	value = strings.TrimSpace(value)
+	return contains(listKeys, key) && strings.HasPrefix(value, "[") ||
+		contains(appendListKeys, key) && strings.HasPrefix(value, "{")
+}
+
+// setValue returns content with key set to value.
+func setValue(content, key, value string) (string, error) {
+	current, err := ParseConfig(strings.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("config file is not valid TOML (fix it with mxt config edit): %w", err)
+	}
+	setting, err := parseSetting(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	doc := parseDocument(content)
+	var updated string
+	if stage, policy, ok := hookKeyStage(key); ok {
+		hook := current.Hooks[stage]
+		if policy {
+			if hook.Command == "" {
+				return "", fmt.Errorf("%s has no command; set %s first", HookKey(stage), HookKey(stage))
+			}
+			hook.OnFailure = setting.Hooks[stage].OnFailure
+		} else {
+			hook.Command = setting.Hooks[stage].Command
+		}
+		encoded, err := encodeHook(hook)
+		if err != nil {
+			return "", err
+		}
+		updated, err = doc.setHook(stage, encoded)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		encoded, err := encodeSetting(setting, key)
+		if err != nil {
+			return "", err
+		}
+		updated = doc.set(key, encoded)
+	}
+
+	if _, err := ParseConfig(strings.NewReader(updated)); err != nil {
+		return "", fmt.Errorf("could not update %s in place (change it with mxt config edit): %w", key, err)
+	}
+	return updated, nil
+}
+
+// unsetValue returns content without key, and whether key was set.
+func unsetValue(content, key string) (string, bool, error) {
+	if err := CheckKey(key); err != nil {
+		return "", false, err
+	}
+	current, err := ParseConfig(strings.NewReader(content))
+	if err != nil {
+		return "", false, fmt.Errorf("config file is not valid TOML (fix it with mxt config edit): %w", err)
+	}
+	if _, ok := current.Lookup(key); !ok {
+		return content, false, nil
+	}
+
+	doc := parseDocument(content)
+	stage, policy, isHook := hookKeyStage(key)
+	if isHook && policy {
+		hook := current.Hooks[stage]
+		hook.OnFailure = ""
+		encoded, err := encodeHook(hook)
+		if err != nil {
+			return "", false, err
+		}
+		updated, err := doc.setHook(stage, encoded)
+		return updated, err == nil, err
+	}
+	if isHook {
+		entry := doc.find(HookKey(stage))
+		if entry == nil {
+			return "", false, fmt.Errorf("%s is not written as a single line; remove it with mxt config edit", key)
+		}
+		return doc.remove(entry), true, nil
+	}
+	entry := doc.find(key)
+	if entry == nil {
+		return "", false, fmt.Errorf("could not find %s in the config file; remove it with mxt config edit", key)
+	}
+	return doc.remove(entry), true, nil
+}
+
+// encodeSetting renders the value a single-key layer sets for key as TOML.
+func encodeSetting(setting *File, key string) (string, error) {
+	var value any
+	switch {
+	case setting.stringField(key) != nil:
+		value = **setting.stringField(key)
+	case setting.boolField(key) != nil:
+		value = **setting.boolField(key)
+	case setting.replaces(key):
+		list, err := encodeTomlValue(*setting.listField(key))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("{ replace = %s }", list), nil
+	default:
+		value = *setting.listField(key)
+	}
+	return encodeTomlValue(value)
+}
+
+// encodeHook renders a hook as a command string, or an inline table when it
+// has an on_failure policy.
+func encodeHook(hook HookConfig) (string, error) {
+	command, err := encodeTomlValue(hook.Command)
+	if err != nil || hook.OnFailure == "" {
+		return command, err
+	}
+	policy, err := encodeTomlValue(hook.OnFailure)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("{ command = %s, on_failure = %s }", command, policy), nil
+}
+
+// encodeTomlValue renders a single value as it appears after "key = ".
+func encodeTomlValue(value any) (string, error) {
+	data, err := toml.Marshal(map[string]any{"value": value})
+	if err != nil {
+		return "", err
+	}
+	_, encoded, _ := strings.Cut(strings.TrimSpace(string(data)), "=")
+	return strings.TrimSpace(encoded), nil
+}
+
+// tomlDocument is a TOML file split into lines, with the position of each
+// table header and key/value entry, so single entries can be rewritten
+// without reformatting the rest of the file.
+type tomlDocument struct {
+	lines   []string
+	tables  []tomlTable
+	entries []tomlEntry
+	newline bool // Whether the content ended with a newline
+}
+
+type tomlTable struct {
+	name string
+	line int
+}
+
+type tomlEntry struct {
+	key     string // Full dotted key, including the table name
+	table   string
+	start   int    // First line of the entry
+	last    int    // Last line of the value
+	prefix  string // The start line up to and including '='
+	comment string // Trailing comment of a single-line entry
+}
+
+func parseDocument(content string) *tomlDocument {
+	doc := &tomlDocument{newline: content == "" || strings.HasSuffix(content, "\n")}
+	if content != "" {
+		doc.lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+	table := ""
+	for i := 0; i < len(doc.lines); i++ {
+		if name, ok := tableHeader(doc.lines[i]); ok {
+			table = name
+			doc.tables = append(doc.tables, tomlTable{name: name, line: i})
+			continue
+		}
+		key, col, ok := entryKey(doc.lines[i])
+		if !ok {
+			continue
+		}
+		last, comment := scanValue(doc.lines, i, col)
+		fullKey := key
+		if table != "" {
+			fullKey = table + "." + key
+		}
+		doc.entries = append(doc.entries, tomlEntry{
+			key:     fullKey,
+			table:   table,
+			start:   i,
+			last:    last,
+			prefix:  doc.lines[i][:col],
+			comment: comment,
+		})
+		i = last
+	}
+	return doc
+}
+
+func (d *tomlDocument) find(key string) *tomlEntry {
+	for i := range d.entries {
+		if d.entries[i].key == key {
+			return &d.entries[i]
+		}
+	}
+	return nil
+}
+
+func (d *tomlDocument) hasTable(name string) bool {
+	for _, table := range d.tables {
+		if table.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// set replaces a top-level key's value, or adds the key after the last
+// top-level entry (before the first table) when it isn't set.
+func (d *tomlDocument) set(key, encoded string) string {
+	if entry := d.find(key); entry != nil {
+		return d.replace(entry, encoded)
+	}
+	line := key + " = " + encoded
+	insertAt := len(d.lines)
+	for _, entry := range d.entries {
+		if entry.table == "" {
+			insertAt = entry.last + 1
+		}
+	}
+	if insertAt == len(d.lines) && len(d.tables) > 0 {
+		first := d.tables[0].line
+		for first > 0 && isCommentOrBlank(d.lines[first-1]) {
+			first-- // Keep the table's leading comments with it
+		}
+		return d.insert(first, line, "")
+	}
+	return d.insert(insertAt, line)
+}
+
+// setHook replaces or adds a stage under [hooks].
+func (d *tomlDocument) setHook(stage, encoded string) (string, error) {
+	if d.hasTable("hooks."+stage) || d.find("hooks") != nil {
+		return "", fmt.Errorf("%s is written as a table; change it with mxt config edit", HookKey(stage))
+	}
+	if entry := d.find(HookKey(stage)); entry != nil {
+		return d.replace(entry, encoded), nil
+	}
+	if !d.hasTable("hooks") {
+		lines := []string{"[hooks]", stage + " = " + encoded}
+		if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+			lines = append([]string{""}, lines...)
+		}
+		return d.insert(len(d.lines), lines...), nil
+	}
+	insertAt := 0
+	for _, table := range d.tables {
+		if table.name == "hooks" {
+			insertAt = table.line + 1
+		}
+	}
+	for _, entry := range d.entries {
+		if entry.table == "hooks" {
+			insertAt = entry.last + 1
+		}
+	}
+	return d.insert(insertAt, stage+" = "+encoded), nil
+}
+
+func (d *tomlDocument) replace(entry *tomlEntry, encoded string) string {
+	line := entry.prefix + " " + encoded
+	if entry.comment != "" {
+		line += " " + entry.comment
+	}
+	lines := append(append(append([]string{}, d.lines[:entry.start]...), line), d.lines[entry.last+1:]...)
+	return d.join(lines)
+}
+
+func (d *tomlDocument) remove(entry *tomlEntry) string {
+	lines := append(append([]string{}, d.lines[:entry.start]...), d.lines[entry.last+1:]...)
+	return d.join(lines)
+}
+
+func (d *tomlDocument) insert(at int, newLines ...string) string {
+	lines := append(append(append([]string{}, d.lines[:at]...), newLines...), d.lines[at:]...)
+	return d.join(lines)
+}
+
+func (d *tomlDocument) join(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	content := strings.Join(lines, "\n")
+	if d.newline || content != "" {
+		content += "\n"
+	}
+	return content
+}
+
+func isCommentOrBlank(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// tableHeader parses a [table] or [[array]] header line.
+func tableHeader(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	trimmed = strings.TrimLeft(trimmed, "[")
+	end := strings.Index(trimmed, "]")
+	if end < 0 {
+		return "", false
+	}
+	parts := strings.Split(trimmed[:end], ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, "."), true
+}
+
+// entryKey parses the (possibly dotted or quoted) key of a key = value line,
+// returning it and the column just after '='.
+func entryKey(line string) (string, int, bool) {
+	var parts []string
+	i := 0
+	for {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i >= len(line) {
+			return "", 0, false
+		}
+		switch c := line[i]; {
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(line[i+1:], c)
+			if end < 0 {
+				return "", 0, false
+			}
+			parts = append(parts, line[i+1:i+1+end])
+			i += end + 2
+		case isBareKeyChar(c):
+			start := i
+			for i < len(line) && isBareKeyChar(line[i]) {
+				i++
+			}
+			parts = append(parts, line[start:i])
+		default:
+			return "", 0, false
+		}
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i < len(line) && line[i] == '.' {
+			i++
+			continue
+		}
+		if i < len(line) && line[i] == '=' {
+			return strings.Join(parts, "."), i + 1, true
+		}
+		return "", 0, false
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// scanValue finds the last line of the value starting at lines[start][col:],
+// following multi-line strings, arrays and inline tables. For a value that
+// ends on its first line it also returns the trailing comment, if any.
+func scanValue(lines []string, start, col int) (int, string) {
+	depth := 0
+	quote := ""
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		j := 0
+		if i == start {
+			j = col
+		}
+		comment := ""
+		for j < len(line) {
+			rest := line[j:]
+			if quote != "" {
+				switch {
+				case rest[0] == '\\' && (quote == `"` || quote == `"""`):
+					j += 2
+				case strings.HasPrefix(rest, quote):
+					j += len(quote)
+					quote = ""
+				default:
+					j++
+				}
+				continue
+			}
+			switch {
+			case strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`):
+				quote = rest[:3]
+				j += 3
+			case rest[0] == '"' || rest[0] == '\'':
+				quote = rest[:1]
+				j++
+			case rest[0] == '[' || rest[0] == '{':
+				depth++
+				j++
+			case rest[0] == ']' || rest[0] == '}':
+				depth--
+				j++
+			case rest[0] == '#':
+				comment = strings.TrimSpace(rest)
+				j = len(line)
+			default:
+				j++
+			}
+		}
+		if quote == `"` || quote == `'` {
+			quote = "" // Single-line strings can't continue onto the next line
+		}
+		if quote == "" && depth <= 0 {
+			if i != start {
+				comment = ""
+			}
+			return i, comment
+		}
+	}
+	return len(lines) - 1, ""
+}
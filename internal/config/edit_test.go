@@ -0,0 +1,211 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editTestConfig = `# mxt configuration (TOML)
+
+# Base directory for worktrees
+worktree_dir = "~/worktrees"  # keep this comment
+
+# Files to copy
+copy_files = [
+  ".env",  # secrets
+  "CLAUDE.md",
+]
+
+# Lifecycle hooks
+[hooks]
+post_create = "bin/setup"
+`
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		key      string
+		value    string
+		expected string
+	}{
+		{
+			name:    "replace scalar keeps comments",
+			content: editTestConfig,
+			key:     "worktree_dir",
+			value:   "~/code/worktrees",
+			expected: strings.Replace(editTestConfig, `worktree_dir = "~/worktrees"  # keep this comment`,
+				`worktree_dir = '~/code/worktrees' # keep this comment`, 1),
+		},
+		{
+			name:    "replace multi-line array",
+			content: editTestConfig,
+			key:     "copy_files",
+			value:   `[".env", "notes,draft.md"]`,
+			expected: strings.Replace(editTestConfig, "copy_files = [\n  \".env\",  # secrets\n  \"CLAUDE.md\",\n]",
+				`copy_files = ['.env', 'notes,draft.md']`, 1),
+		},
+		{
+			name:    "new key goes before the first table",
+			content: editTestConfig,
+			key:     "fetch_before_new",
+			value:   "true",
+			expected: strings.Replace(editTestConfig, "  \"CLAUDE.md\",\n]\n",
+				"  \"CLAUDE.md\",\n]\nfetch_before_new = true\n", 1),
+		},
+		{
+			name:     "comma-separated list",
+			content:  "",
+			key:      "sparse_paths",
+			value:    "packages/api, libs/shared",
+			expected: "sparse_paths = ['packages/api', 'libs/shared']\n",
+		},
+		{
+			name:     "replace form",
+			content:  "",
+			key:      "copy_files",
+			value:    `{ replace = [".env.local"] }`,
+			expected: "copy_files = { replace = ['.env.local'] }\n",
+		},
+		{
+			name:     "new key in table-only file",
+			content:  "# hooks\n[hooks]\npost_create = \"bin/setup\"\n",
+			key:      "terminal",
+			value:    "iterm2",
+			expected: "terminal = 'iterm2'\n\n# hooks\n[hooks]\npost_create = \"bin/setup\"\n",
+		},
+		{
+			name:    "hook command in existing table",
+			content: editTestConfig,
+			key:     "hooks.pre_delete",
+			value:   "bin/dump > dump.sql",
+			expected: strings.Replace(editTestConfig, "post_create = \"bin/setup\"\n",
+				"post_create = \"bin/setup\"\npre_delete = 'bin/dump > dump.sql'\n", 1),
+		},
+		{
+			name:    "hook policy turns the entry into a table",
+			content: editTestConfig,
+			key:     "hooks.post_create.on_failure",
+			value:   "warn",
+			expected: strings.Replace(editTestConfig, `post_create = "bin/setup"`,
+				`post_create = { command = 'bin/setup', on_failure = 'warn' }`, 1),
+		},
+		{
+			name:     "hooks table created",
+			content:  "terminal = \"iterm2\"\n",
+			key:      "hooks.post_create",
+			value:    "bin/setup",
+			expected: "terminal = \"iterm2\"\n\n[hooks]\npost_create = 'bin/setup'\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setValue(tt.content, tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("setValue() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("setValue() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetValueRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+	}{
+		{"unknown key", "", "worktree_path", "/tmp"},
+		{"shell metacharacters", "", "terminal", "iterm2; rm -rf /"},
+		{"metacharacters in list entry", "", "copy_files", `[".env", "$(whoami)"]`},
+		{"invalid enum", "", "pre_session_mode", "background"},
+		{"invalid bool", "", "lfs_pull", "yes"},
+		{"replace form with other keys", "", "copy_files", `{ replace = [".env"], append = ["CLAUDE.md"] }`},
+		{"policy without command", "", "hooks.pre_delete.on_failure", "abort"},
+		{"hook written as table", "[hooks.post_create]\ncommand = \"bin/setup\"\n", "hooks.post_create", "bin/other"},
+		{"broken file", "terminal = ", "terminal", "iterm2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := setValue(tt.content, tt.key, tt.value); err == nil {
+				t.Errorf("setValue(%q, %q) expected error", tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestUnsetValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		expected string
+		removed  bool
+	}{
+		{
+			name:     "scalar",
+			key:      "worktree_dir",
+			expected: strings.Replace(editTestConfig, "worktree_dir = \"~/worktrees\"  # keep this comment\n", "", 1),
+			removed:  true,
+		},
+		{
+			name:     "multi-line array",
+			key:      "copy_files",
+			expected: strings.Replace(editTestConfig, "copy_files = [\n  \".env\",  # secrets\n  \"CLAUDE.md\",\n]\n", "", 1),
+			removed:  true,
+		},
+		{
+			name:     "hook",
+			key:      "hooks.post_create",
+			expected: strings.Replace(editTestConfig, "post_create = \"bin/setup\"\n", "", 1),
+			removed:  true,
+		},
+		{
+			name:     "not set",
+			key:      "terminal",
+			expected: editTestConfig,
+			removed:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed, err := unsetValue(editTestConfig, tt.key)
+			if err != nil {
+				t.Fatalf("unsetValue() error = %v", err)
+			}
+			if removed != tt.removed || got != tt.expected {
+				t.Errorf("unsetValue() = %v,\n%s\nwant %v,\n%s", removed, got, tt.removed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetConfigValueCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mxt", "config.toml")
+	if err := SetConfigValue(path, "copy_files", `["a,b.txt"]`); err != nil {
+		t.Fatalf("SetConfigValue() error = %v", err)
+	}
+	file, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	if values, _ := file.Lookup("copy_files"); len(values) != 1 || values[0] != "a,b.txt" {
+		t.Errorf("copy_files = %q, want [a,b.txt]", values)
+	}
+
+	removed, err := UnsetConfigValue(path, "copy_files")
+	if err != nil || !removed {
+		t.Fatalf("UnsetConfigValue() = %v, %v", removed, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "" {
+		t.Errorf("config after unset = %q, want empty", data)
+	}
+}
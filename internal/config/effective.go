@@ -0,0 +1,113 @@
+package config
+
+// Setting is one key of the effective configuration and the layer that
+// supplied it.
+type Setting struct {
+	Key   string
+	Value string // TOML-encoded, as it would appear after "key = "
+	Layer Layer
+	Added []Layer // lower layers whose copy_files/copy_ignored entries come first
+}
+
+// Effective returns the merged value of every key across layers (lowest
+// priority first, as returned by LoadLayers) with the layer each came from.
+// Keys no layer sets, such as the list keys, are reported as empty defaults.
+// Hook keys appear only when a layer sets them.
+func Effective(layers []Layer) ([]Setting, error) {
+	var settings []Setting
+	for _, key := range Keys() {
+		if contains(appendListKeys, key) {
+			setting, err := effectiveAppendList(layers, key)
+			if err != nil {
+				return nil, err
+			}
+			settings = append(settings, setting)
+			continue
+		}
+		stage, policy, isHook := hookKeyStage(key)
+		layer, found := supplyingLayer(layers, key, stage, isHook, policy)
+		if !found {
+			if isHook {
+				continue
+			}
+			layer = Layer{Source: SourceDefault, File: &File{}}
+		}
+
+		var value any
+		switch {
+		case policy:
+			value = layer.File.Hooks[stage].OnFailure
+		case isHook:
+			value = layer.File.Hooks[stage].Command
+		case layer.File.listField(key) != nil:
+			list := *layer.File.listField(key)
+			if list == nil {
+				list = []string{}
+			}
+			value = list
+		case layer.File.boolField(key) != nil:
+			value = boolValue(*layer.File.boolField(key))
+		default:
+			value = stringValue(*layer.File.stringField(key))
+		}
+
+		encoded, err := encodeTomlValue(value)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, Setting{Key: key, Value: encoded, Layer: layer})
+	}
+	return settings, nil
+}
+
+// effectiveAppendList merges an append list key the way MergeConfigs does,
+// recording every layer whose entries are part of the result.
+func effectiveAppendList(layers []Layer, key string) (Setting, error) {
+	list := []string{}
+	var supplying []Layer
+	for _, layer := range layers {
+		value := *layer.File.listField(key)
+		switch {
+		case value == nil:
+			continue
+		case layer.File.replaces(key):
+			list, supplying = value, nil
+		default:
+			list = appendNew(list, value)
+		}
+		supplying = append(supplying, layer)
+	}
+	if len(supplying) == 0 {
+		supplying = []Layer{{Source: SourceDefault, File: &File{}}}
+	}
+
+	encoded, err := encodeTomlValue(list)
+	if err != nil {
+		return Setting{}, err
+	}
+	last := len(supplying) - 1
+	return Setting{Key: key, Value: encoded, Layer: supplying[last], Added: supplying[:last:last]}, nil
+}
+
+// supplyingLayer returns the highest-priority layer that sets key. For hooks
+// this follows MergeConfigs: a stage set without on_failure hides the
+// policies of lower layers, and a policy-only stage doesn't supply a command.
+func supplyingLayer(layers []Layer, key, stage string, isHook, policy bool) (Layer, bool) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if isHook {
+			hook, ok := layers[i].File.Hooks[stage]
+			switch {
+			case !ok || (hook.policyOnly() && !policy):
+				continue
+			case policy && hook.OnFailure == "":
+				// The stage's command was set here without a policy
+				return Layer{}, false
+			}
+			return layers[i], true
+		}
+		if _, ok := layers[i].File.Lookup(key); ok {
+			return layers[i], true
+		}
+	}
+	return Layer{}, false
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestEffective(t *testing.T) {
+	defaults := Layer{Source: SourceDefault, File: &File{
+		Terminal: stringPtr(DefaultTerminal),
+		LFSPull:  boolPtr(DefaultLFSPull),
+	}}
+	global := Layer{Source: SourceGlobal, Path: "/home/me/.config/mxt/config.toml", File: &File{
+		Terminal:    stringPtr("iterm2"),
+		CopyFiles:   []string{".env"},
+		CopyIgnored: []string{"node_modules"},
+		Hooks: map[string]HookConfig{
+			"post_create": {Command: "bin/setup", OnFailure: "warn"},
+			"pre_delete":  {Command: "bin/dump", OnFailure: "abort"},
+		},
+	}}
+	project := Layer{Source: SourceProject, Path: "/src/app/.mxt.toml", File: &File{
+		CopyFiles:    []string{"CLAUDE.md"},
+		CopyIgnored:  []string{},
+		ReplaceLists: []string{"copy_ignored"},
+		LFSPull:      boolPtr(true),
+		Hooks: map[string]HookConfig{
+			"pre_delete": {Command: "bin/dump-db"},
+		},
+	}}
+
+	settings, err := Effective([]Layer{defaults, global, project})
+	if err != nil {
+		t.Fatalf("Effective() error = %v", err)
+	}
+	got := map[string]Setting{}
+	for _, setting := range settings {
+		got[setting.Key] = setting
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source string
+	}{
+		{"terminal", "'iterm2'", SourceGlobal},
+		{"lfs_pull", "true", SourceProject},
+		{"copy_files", "['.env', 'CLAUDE.md']", SourceProject},
+		{"copy_ignored", "[]", SourceProject},
+		{"tmux_layout", "[]", SourceDefault},
+		{"worktree_dir", "''", SourceDefault},
+		{"hooks.post_create", "'bin/setup'", SourceGlobal},
+		{"hooks.post_create.on_failure", "'warn'", SourceGlobal},
+		{"hooks.pre_delete", "'bin/dump-db'", SourceProject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			setting, ok := got[tt.key]
+			if !ok {
+				t.Fatalf("%s missing from Effective()", tt.key)
+			}
+			if setting.Value != tt.value || setting.Layer.Source != tt.source {
+				t.Errorf("%s = %s from %s, want %s from %s", tt.key, setting.Value, setting.Layer.Source, tt.value, tt.source)
+			}
+		})
+	}
+
+	if added := got["copy_files"].Added; len(added) != 1 || added[0].Source != SourceGlobal {
+		t.Errorf("copy_files added from %+v, want the global layer", added)
+	}
+	if added := got["copy_ignored"].Added; len(added) != 0 {
+		t.Errorf("copy_ignored added from %+v, want none after { replace = [] }", added)
+	}
+
+	// The project's pre_delete replaces the global one, policy included
+	for _, key := range []string{"hooks.pre_delete.on_failure", "hooks.post_delete", "hooks.on_session_open"} {
+		if setting, ok := got[key]; ok {
+			t.Errorf("%s = %s from %s, want unset", key, setting.Value, setting.Layer.Source)
+		}
+	}
+}
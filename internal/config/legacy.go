@@ -131,3 +131,130 @@ func ParseLegacyConfig(r io.Reader) (map[string]string, error) {
 
 	return config, nil
 }
+
+// ConvertLegacyConfig converts parsed legacy key=value settings into a config
+// layer, applying the same validation as TOML config files. Comma-separated
+// values become lists, as does the normalized tmux_layout.
+func ConvertLegacyConfig(legacy map[string]string) (*File, error) {
+	raw := make(map[string]any, len(legacy))
+	for key, value := range legacy {
+		raw[key] = value
+	}
+	return decodeFile(raw)
+}
+
+// normalizeTmuxLayout normalizes separators in tmux_layout values.
+// It converts commas to semicolons and handles space-separated window definitions.
+// Window definitions are in format: window_name:command|command
+// Multiple windows can be separated by commas, semicolons, or newlines (spaces in multi-line)
+func normalizeTmuxLayout(layout string) string {
+	layout = strings.ReplaceAll(layout, "\n", " ")
+	layout = strings.ReplaceAll(layout, "\r", " ")
+	// First, replace commas with semicolons
+	layout = strings.ReplaceAll(layout, ",", ";")
+
+	// If already contains semicolons, we're mostly done
+	if strings.Contains(layout, ";") {
+		// Clean up double semicolons
+		for strings.Contains(layout, ";;") {
+			layout = strings.ReplaceAll(layout, ";;", ";")
+		}
+		// Trim leading/trailing semicolons
+		layout = strings.Trim(layout, ";")
+		return layout
+	}
+
+	// No semicolons yet - this means it's space-separated (from multi-line array)
+	// We need to detect window boundaries by looking for window_name: pattern
+	// A window starts with word(s) followed by colon
+
+	// Strategy: look for pattern where we see "word:" that starts a window
+	// We'll scan character by character to find word: patterns
+	var result []string
+	var currentWindow strings.Builder
+
+	runes := []rune(layout)
+	i := 0
+
+	for i < len(runes) {
+		// Skip leading whitespace
+		for i < len(runes) && (runes[i] == ' ' || runes[i] == '\t') {
+			i++
+		}
+
+		if i >= len(runes) {
+			break
+		}
+
+		// Check if this looks like start of window (word followed by :)
+		// Look ahead to find the next colon
+		colonPos := -1
+		for j := i; j < len(runes); j++ {
+			if runes[j] == ':' {
+				colonPos = j
+				break
+			}
+			if runes[j] == ' ' || runes[j] == '\t' {
+				// Space before colon, not a window name
+				break
+			}
+		}
+
+		// If we found a colon and we already have content, start new window
+		if colonPos != -1 && currentWindow.Len() > 0 {
+			result = append(result, strings.TrimSpace(currentWindow.String()))
+			currentWindow.Reset()
+		}
+
+		// Read until we find the next potential window start or end
+		if colonPos != -1 {
+			// Read through this window definition
+			// Find the end - either next window name pattern or end of string
+			windowEnd := len(runes)
+
+			// Look ahead for next window pattern (space followed by word:)
+			for j := colonPos + 1; j < len(runes)-1; j++ {
+				if runes[j] == ' ' {
+					// Check if what follows looks like window_name:
+					k := j + 1
+					for k < len(runes) && runes[k] == ' ' {
+						k++
+					}
+					if k < len(runes) {
+						// Look for : after the word
+						hasColon := false
+						for m := k; m < len(runes) && runes[m] != ' '; m++ {
+							if runes[m] == ':' {
+								hasColon = true
+								windowEnd = j
+								break
+							}
+						}
+						if hasColon {
+							break
+						}
+					}
+				}
+			}
+
+			currentWindow.WriteString(string(runes[i:windowEnd]))
+			i = windowEnd
+		} else {
+			// No colon found, just read to end
+			currentWindow.WriteString(string(runes[i:]))
+			break
+		}
+	}
+
+	// Don't forget the last window
+	if currentWindow.Len() > 0 {
+		result = append(result, strings.TrimSpace(currentWindow.String()))
+	}
+
+	// Join with semicolons
+	if len(result) > 0 {
+		layout = strings.Join(result, ";")
+	}
+
+	return layout
+}
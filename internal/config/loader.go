@@ -10,45 +10,129 @@ import (
 
 // Default configuration values
 const (
-	DefaultTerminal      = "terminal"
-	DefaultSandboxTool   = ""
-	DefaultCopyFiles     = ""
-	DefaultPreSessionCmd = ""
-	DefaultTmuxLayout    = ""
+	DefaultTerminal          = "terminal"
+	DefaultSandboxTool       = ""
+	DefaultPreSessionCmd     = ""
+	DefaultPreSessionMode    = "block"
+	DefaultPreSessionTimeout = ""
+	DefaultFetchBeforeNew    = false
+	DefaultRemote            = "origin"
+	DefaultBaseBranch        = ""
+	DefaultInitSubmodules    = false
+	DefaultLFSPull           = false
 )
 
-// LoadDefaults returns the default configuration
-func LoadDefaults() (map[string]string, error) {
+// LoadDefaults returns the default configuration layer. List keys
+// (copy_files, copy_ignored, tmux_layout, sparse_paths) default to empty.
+func LoadDefaults() (*File, error) {
 	home := os.Getenv("HOME")
 	if home == "" {
 		return nil, fmt.Errorf("HOME environment variable not set")
 	}
 
-	return map[string]string{
-		"worktree_dir":    filepath.Join(home, "worktrees"),
-		"terminal":        DefaultTerminal,
-		"sandbox_tool":    DefaultSandboxTool,
-		"copy_files":      DefaultCopyFiles,
-		"pre_session_cmd": DefaultPreSessionCmd,
-		"tmux_layout":     DefaultTmuxLayout,
+	return &File{
+		WorktreeDir:       stringPtr(filepath.Join(home, "worktrees")),
+		Terminal:          stringPtr(DefaultTerminal),
+		SandboxTool:       stringPtr(DefaultSandboxTool),
+		PreSessionCmd:     stringPtr(DefaultPreSessionCmd),
+		PreSessionMode:    stringPtr(DefaultPreSessionMode),
+		PreSessionTimeout: stringPtr(DefaultPreSessionTimeout),
+		FetchBeforeNew:    boolPtr(DefaultFetchBeforeNew),
+		Remote:            stringPtr(DefaultRemote),
+		BaseBranch:        stringPtr(DefaultBaseBranch),
+		InitSubmodules:    boolPtr(DefaultInitSubmodules),
+		LFSPull:           boolPtr(DefaultLFSPull),
 	}, nil
 }
 
-// MergeConfigs merges two config maps, with override taking precedence
-func MergeConfigs(base, override map[string]string) map[string]string {
-	result := make(map[string]string)
+func stringPtr(value string) *string {
+	return &value
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+// MergeConfigs layers override on top of base, returning a new File.
+// Merge semantics per key:
+//   - Scalars set in override replace the base value, as do tmux_layout and
+//     sparse_paths
+//   - copy_files and copy_ignored entries in override are added after those
+//     of base, skipping any already there; written as { replace = [...] }
+//     they replace them, so
+//     copy_files = { replace = [] } clears the list
+//   - [hooks] merges per stage: override replaces the stages it sets
+//     (command and on_failure together) and keeps the others from base. A
+//     stage with an on_failure but no command, as set by
+//     MXT_HOOKS_<STAGE>_ON_FAILURE, only changes the policy
+//   - [profiles] merges per profile, and each profile key by key, so a
+//     project can add to a global profile of the same name
+//   - [[rules]] from override are appended to those from base
+func MergeConfigs(base, override *File) *File {
+	result := *base
+
+	for _, key := range stringKeys {
+		if value := *override.stringField(key); value != nil {
+			*result.stringField(key) = value
+		}
+	}
+	for _, key := range boolKeys {
+		if value := *override.boolField(key); value != nil {
+			*result.boolField(key) = value
+		}
+	}
+	result.ReplaceLists = nil
+	for _, key := range listKeys {
+		value := *override.listField(key)
+		switch {
+		case value == nil:
+			if base.replaces(key) {
+				result.ReplaceLists = append(result.ReplaceLists, key)
+			}
+		case contains(appendListKeys, key) && !override.replaces(key):
+			*result.listField(key) = appendNew(*base.listField(key), value)
+			if base.replaces(key) {
+				result.ReplaceLists = append(result.ReplaceLists, key)
+			}
+		default:
+			*result.listField(key) = value
+			if override.replaces(key) {
+				result.ReplaceLists = append(result.ReplaceLists, key)
+			}
+		}
+	}
 
-	// Copy base
-	for k, v := range base {
-		result[k] = v
+	if len(override.Hooks) > 0 {
+		result.Hooks = make(map[string]HookConfig, len(base.Hooks)+len(override.Hooks))
+		for stage, hook := range base.Hooks {
+			result.Hooks[stage] = hook
+		}
+		for stage, hook := range override.Hooks {
+			if hook.policyOnly() {
+				hook.Command = base.Hooks[stage].Command
+			}
+			result.Hooks[stage] = hook
+		}
+	}
+
+	if len(override.Profiles) > 0 {
+		result.Profiles = make(map[string]*File, len(base.Profiles)+len(override.Profiles))
+		for name, profile := range base.Profiles {
+			result.Profiles[name] = profile
+		}
+		for name, profile := range override.Profiles {
+			if baseProfile, ok := base.Profiles[name]; ok {
+				profile = MergeConfigs(baseProfile, profile)
+			}
+			result.Profiles[name] = profile
+		}
 	}
 
-	// Override with values from override map
-	for k, v := range override {
-		result[k] = v
+	if len(override.Rules) > 0 {
+		result.Rules = append(append([]Rule{}, base.Rules...), override.Rules...)
 	}
 
-	return result
+	return &result
 }
 
 // ExpandTilde expands ~ at the start of a path to the user's home directory
@@ -68,12 +152,22 @@ func ExpandTilde(path string) string {
 	return path
 }
 
-// LoadConfigFile loads a config file from the given path.
-// Returns an empty map (not an error) if the file doesn't exist.
-func LoadConfigFile(path string) (map[string]string, error) {
+// LoadConfigFile loads a config file from the given path, with the files
+// its include key lists merged in below its own values.
+// Returns an empty layer (not an error) if the file doesn't exist.
+func LoadConfigFile(path string) (*File, error) {
+	return loadConfigFile(path, nil)
+}
+
+// loadConfigFile is LoadConfigFile for a file reached through the includes
+// of each file in including, which it must not include again.
+func loadConfigFile(path string, including []string) (*File, error) {
 	// Check if file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return make(map[string]string), nil
+		if len(including) > 0 {
+			return nil, fmt.Errorf("config file %s included by %s not found", path, including[len(including)-1])
+		}
+		return &File{}, nil
 	}
 
 	// Open file
@@ -94,7 +188,46 @@ func LoadConfigFile(path string) (map[string]string, error) {
 		return nil, fmt.Errorf("config file %s failed security validation: %w", path, err)
 	}
 
-	return config, nil
+	return resolveIncludes(path, config, including)
+}
+
+// resolveIncludes merges the files listed by the include key of the config
+// read from path, in order, then the file's own values on top. Include paths
+// may start with ~ and are relative to the including file's directory.
+func resolveIncludes(path string, file *File, including []string) (*File, error) {
+	if len(file.Include) == 0 {
+		return file, nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	chain := append(append([]string{}, including...), absPath)
+
+	merged := &File{}
+	var included []string
+	for _, entry := range file.Include {
+		includePath := ExpandTilde(entry)
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(absPath), includePath)
+		}
+		includePath = filepath.Clean(includePath)
+		if contains(chain, includePath) {
+			return nil, fmt.Errorf("config include cycle: %s", strings.Join(append(chain, includePath), " -> "))
+		}
+
+		includedFile, err := loadConfigFile(includePath, chain)
+		if err != nil {
+			return nil, err
+		}
+		merged = MergeConfigs(merged, includedFile)
+		included = append(append(included, includedFile.Included...), includePath)
+	}
+
+	result := MergeConfigs(merged, file)
+	result.Include = file.Include
+	result.Included = included
+	return result, nil
 }
 
 // FindGitRoot finds the root directory of the git repository containing the given path.
@@ -143,41 +276,172 @@ func GetLegacyProjectConfigPath(gitRoot string) string {
 	return filepath.Join(gitRoot, ".mxt")
 }
 
-// LoadConfig loads configuration with proper priority:
-// 1. Load defaults
-// 2. Load global config (if exists) - overrides defaults
-// 3. Detect git repo and load project config (if exists) - overrides global
-// 4. Expand tilde in worktree_dir
-func LoadConfig(workDir string) (map[string]string, error) {
-	// Start with defaults
-	config, err := LoadDefaults()
+// Layer sources, lowest priority first.
+const (
+	SourceDefault = "default"
+	SourceGlobal  = "global"
+	SourceProject = "project"
+	SourceRule    = "rule"
+	SourceProfile = "profile"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Layer is one source of configuration.
+type Layer struct {
+	Source string // One of the Source* constants
+	Path   string // Config file the layer was read from; empty for defaults
+	Name   string // Profile name, or branch glob for SourceRule layers
+	File   *File
+
+	// Untrusted is set on a project layer whose command keys were ignored
+	// because the file isn't trusted (see Trust)
+	Untrusted bool
+}
+
+// LoadLayers loads every configuration layer that applies in workDir,
+// lowest priority first:
+// 1. Defaults
+// 2. Global config (empty if the file doesn't exist)
+// 3. Project config, when workDir is inside a git repo (empty if the file
+// doesn't exist), without its command keys unless it is trusted
+// 4. Each [[rules]] table whose glob matches branch, global rules before
+// project rules (omitted when branch is empty)
+// 5. The named profile, or default_profile when profile is empty (omitted
+// when neither is set)
+// 6. MXT_* environment variables (see EnvVar)
+// 7. --set/-c overrides (see SetFlagOverrides)
+func LoadLayers(workDir, branch, profile string) ([]Layer, error) {
+	defaults, err := LoadDefaults()
 	if err != nil {
 		return nil, err
 	}
+	layers := []Layer{{Source: SourceDefault, File: defaults}}
 
-	// Load global config
 	globalConfigPath := GetGlobalConfigPath()
 	globalConfig, err := LoadConfigFile(globalConfigPath)
 	if err != nil {
 		return nil, err
 	}
-	config = MergeConfigs(config, globalConfig)
+	layers = append(layers, Layer{Source: SourceGlobal, Path: globalConfigPath, File: globalConfig})
 
-	// Try to find git root and load project config
-	gitRoot, err := FindGitRoot(workDir)
-	if err == nil {
-		// We're in a git repo, try to load project config
+	// If not in a git repo, that's okay - just use global config
+	if gitRoot, err := FindGitRoot(workDir); err == nil {
 		projectConfigPath := GetProjectConfigPath(gitRoot)
 		projectConfig, err := LoadConfigFile(projectConfigPath)
 		if err != nil {
 			return nil, err
 		}
-		config = MergeConfigs(config, projectConfig)
+		projectConfig, untrusted, err := trustedProjectConfig(projectConfigPath, projectConfig)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, Layer{Source: SourceProject, Path: projectConfigPath, File: projectConfig, Untrusted: untrusted})
 	}
-	// If not in a git repo, that's okay - just use global config
 
-	// Expand tilde in worktree_dir
-	config["worktree_dir"] = ExpandTilde(config["worktree_dir"])
+	envConfig, err := loadEnvOverrides()
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, Layer{Source: SourceEnv, File: envConfig})
+
+	flagConfig, err := parseOverrides(flagOverrides)
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, Layer{Source: SourceFlag, File: flagConfig})
+
+	return withProfile(withRules(layers, branch), profile)
+}
+
+// withRules inserts a layer for each rule in the config files that matches
+// branch, below the env and flag layers.
+func withRules(layers []Layer, branch string) []Layer {
+	if branch == "" {
+		return layers
+	}
+	var rules []Layer
+	for _, layer := range layers {
+		if layer.Source != SourceGlobal && layer.Source != SourceProject {
+			continue
+		}
+		for _, rule := range layer.File.Rules {
+			if rule.matches(branch) {
+				rules = append(rules, Layer{Source: SourceRule, Path: layer.Path, Name: rule.Branch, File: rule.File})
+			}
+		}
+	}
+	return insertBelowEnv(layers, rules...)
+}
+
+// withProfile inserts the profile layer below the env and flag layers. The
+// profile is looked up in, and default_profile read from, all other layers
+// merged, so MXT_DEFAULT_PROFILE and -c default_profile=<name> work too.
+func withProfile(layers []Layer, profile string) ([]Layer, error) {
+	merged := &File{}
+	for _, layer := range layers {
+		merged = MergeConfigs(merged, layer.File)
+	}
+	if profile == "" {
+		profile = stringValue(merged.DefaultProfile)
+	}
+	if profile == "" {
+		return layers, nil
+	}
 
-	return config, nil
+	settings, ok := merged.Profiles[profile]
+	if !ok {
+		if len(merged.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q (no [profiles] configured)", profile)
+		}
+		return nil, fmt.Errorf("unknown profile %q (use %s)", profile, strings.Join(sortedKeys(merged.Profiles), ", "))
+	}
+
+	return insertBelowEnv(layers, Layer{Source: SourceProfile, Name: profile, File: settings}), nil
+}
+
+// insertBelowEnv returns layers with extra inserted just below the env layer.
+func insertBelowEnv(layers []Layer, extra ...Layer) []Layer {
+	position := len(layers)
+	for i, layer := range layers {
+		if layer.Source == SourceEnv {
+			position = i
+			break
+		}
+	}
+	result := append([]Layer{}, layers[:position]...)
+	result = append(result, extra...)
+	return append(result, layers[position:]...)
+}
+
+// LoadConfig merges the layers from LoadLayers, each overriding the ones
+// before it, and resolves the result, expanding tilde in worktree_dir.
+// default_profile applies when set; use LoadConfigFor to pick a profile.
+func LoadConfig(workDir string) (*Config, error) {
+	return LoadConfigFor(workDir, "", "")
+}
+
+// LoadConfigFor is LoadConfig for a branch: the [[rules]] matching it apply
+// above the project config, then the named profile. An empty profile means
+// default_profile.
+func LoadConfigFor(workDir, branch, profile string) (*Config, error) {
+	layers, err := LoadLayers(workDir, branch, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &File{}
+	for _, layer := range layers {
+		merged = MergeConfigs(merged, layer.File)
+	}
+	cfg, err := resolve(merged)
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range layers {
+		if layer.Source == SourceProfile {
+			cfg.Profile = layer.Name
+		}
+	}
+	return cfg, nil
 }
@@ -4,6 +4,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -16,79 +18,114 @@ func TestLoadDefaults(t *testing.T) {
 
 	// Check default values
 	expectedDefaults := map[string]string{
-		"worktree_dir":    filepath.Join(os.Getenv("HOME"), "worktrees"),
-		"terminal":        "terminal",
-		"sandbox_tool":    "",
-		"copy_files":      "",
-		"pre_session_cmd": "",
-		"tmux_layout":     "",
+		"worktree_dir":     filepath.Join(os.Getenv("HOME"), "worktrees"),
+		"terminal":         "terminal",
+		"sandbox_tool":     "",
+		"pre_session_cmd":  "",
+		"pre_session_mode": "block",
+		"remote":           "origin",
 	}
 
 	for key, expectedVal := range expectedDefaults {
-		if config[key] != expectedVal {
-			t.Errorf("LoadDefaults()[%q] = %q, want %q", key, config[key], expectedVal)
+		value := *config.stringField(key)
+		if value == nil || *value != expectedVal {
+			t.Errorf("LoadDefaults()[%q] = %v, want %q", key, value, expectedVal)
 		}
 	}
+	if config.CopyFiles != nil || config.TmuxLayout != nil {
+		t.Errorf("LoadDefaults() lists = %q, %q, want unset", config.CopyFiles, config.TmuxLayout)
+	}
 }
 
-// TestMergeConfigs tests merging two config maps
+// TestMergeConfigs tests layering one config over another
 func TestMergeConfigs(t *testing.T) {
 	tests := []struct {
 		name     string
-		base     map[string]string
-		override map[string]string
-		expected map[string]string
+		base     *File
+		override *File
+		expected *File
 	}{
 		{
-			name: "override replaces values",
-			base: map[string]string{
-				"worktree_dir": "/base/path",
-				"terminal":     "terminal",
-			},
-			override: map[string]string{
-				"terminal": "iterm2",
-			},
-			expected: map[string]string{
-				"worktree_dir": "/base/path",
-				"terminal":     "iterm2",
-			},
+			name:     "override replaces values",
+			base:     &File{WorktreeDir: stringPtr("/base/path"), Terminal: stringPtr("terminal")},
+			override: &File{Terminal: stringPtr("iterm2")},
+			expected: &File{WorktreeDir: stringPtr("/base/path"), Terminal: stringPtr("iterm2")},
+		},
+		{
+			name:     "override adds new keys",
+			base:     &File{WorktreeDir: stringPtr("/base/path")},
+			override: &File{Terminal: stringPtr("iterm2"), CopyFiles: []string{".env"}, LFSPull: boolPtr(true)},
+			expected: &File{WorktreeDir: stringPtr("/base/path"), Terminal: stringPtr("iterm2"), CopyFiles: []string{".env"}, LFSPull: boolPtr(true)},
+		},
+		{
+			name:     "empty override keeps base",
+			base:     &File{WorktreeDir: stringPtr("/base/path"), CopyFiles: []string{".env"}, FetchBeforeNew: boolPtr(true)},
+			override: &File{},
+			expected: &File{WorktreeDir: stringPtr("/base/path"), CopyFiles: []string{".env"}, FetchBeforeNew: boolPtr(true)},
+		},
+		{
+			name:     "copy lists append without duplicates",
+			base:     &File{CopyFiles: []string{".env", "CLAUDE.md"}, CopyIgnored: []string{"node_modules"}},
+			override: &File{CopyFiles: []string{".env.local", ".env"}, CopyIgnored: []string{}},
+			expected: &File{CopyFiles: []string{".env", "CLAUDE.md", ".env.local"}, CopyIgnored: []string{"node_modules"}},
+		},
+		{
+			name:     "layout and sparse paths replace",
+			base:     &File{TmuxLayout: []string{"dev:", "agent:"}, SparsePaths: []string{"api"}},
+			override: &File{TmuxLayout: []string{"shell:"}, SparsePaths: []string{}},
+			expected: &File{TmuxLayout: []string{"shell:"}, SparsePaths: []string{}},
+		},
+		{
+			name:     "replace form replaces and clears",
+			base:     &File{CopyFiles: []string{".env"}, CopyIgnored: []string{"node_modules"}},
+			override: &File{CopyFiles: []string{".env.local"}, CopyIgnored: []string{}, ReplaceLists: []string{"copy_files", "copy_ignored"}},
+			expected: &File{CopyFiles: []string{".env.local"}, CopyIgnored: []string{}, ReplaceLists: []string{"copy_files", "copy_ignored"}},
+		},
+		{
+			name:     "replace marker survives layers that don't set the key",
+			base:     &File{CopyFiles: []string{".env"}, ReplaceLists: []string{"copy_files"}},
+			override: &File{WorktreeDir: stringPtr("/wt")},
+			expected: &File{WorktreeDir: stringPtr("/wt"), CopyFiles: []string{".env"}, ReplaceLists: []string{"copy_files"}},
+		},
+		{
+			name:     "appending keeps the base's replace marker",
+			base:     &File{CopyFiles: []string{".env"}, ReplaceLists: []string{"copy_files"}},
+			override: &File{CopyFiles: []string{"CLAUDE.md"}},
+			expected: &File{CopyFiles: []string{".env", "CLAUDE.md"}, ReplaceLists: []string{"copy_files"}},
 		},
 		{
-			name: "override adds new keys",
-			base: map[string]string{
-				"worktree_dir": "/base/path",
-			},
-			override: map[string]string{
-				"terminal":   "iterm2",
-				"copy_files": ".env",
-			},
-			expected: map[string]string{
-				"worktree_dir": "/base/path",
-				"terminal":     "iterm2",
-				"copy_files":   ".env",
-			},
+			name: "hooks merge per stage",
+			base: &File{Hooks: map[string]HookConfig{
+				"post_create": {Command: "bin/setup"},
+				"pre_delete":  {Command: "bin/dump", OnFailure: "abort"},
+			}},
+			override: &File{Hooks: map[string]HookConfig{
+				"pre_delete": {Command: "bin/dump-db"},
+			}},
+			expected: &File{Hooks: map[string]HookConfig{
+				"post_create": {Command: "bin/setup"},
+				"pre_delete":  {Command: "bin/dump-db"},
+			}},
 		},
 		{
-			name: "empty override keeps base",
-			base: map[string]string{
-				"worktree_dir": "/base/path",
-				"terminal":     "terminal",
-			},
-			override: map[string]string{},
-			expected: map[string]string{
-				"worktree_dir": "/base/path",
-				"terminal":     "terminal",
-			},
+			name: "policy-only hook keeps base command",
+			base: &File{Hooks: map[string]HookConfig{
+				"pre_delete": {Command: "bin/dump", OnFailure: "abort"},
+			}},
+			override: &File{Hooks: map[string]HookConfig{
+				"pre_delete": {OnFailure: "warn"},
+			}},
+			expected: &File{Hooks: map[string]HookConfig{
+				"pre_delete": {Command: "bin/dump", OnFailure: "warn"},
+			}},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := MergeConfigs(tt.base, tt.override)
-			for key, expectedVal := range tt.expected {
-				if result[key] != expectedVal {
-					t.Errorf("MergeConfigs()[%q] = %q, want %q", key, result[key], expectedVal)
-				}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("MergeConfigs() = %+v, want %+v", result, tt.expected)
 			}
 		})
 	}
@@ -163,16 +200,13 @@ copy_files = ".env,.env.local"
 		t.Fatalf("LoadConfigFile() error = %v", err)
 	}
 
-	expected := map[string]string{
-		"worktree_dir": "~/test-worktrees",
-		"terminal":     "iterm2",
-		"copy_files":   ".env,.env.local",
+	expected := &File{
+		WorktreeDir: stringPtr("~/test-worktrees"),
+		Terminal:    stringPtr("iterm2"),
+		CopyFiles:   []string{".env", ".env.local"},
 	}
-
-	for key, expectedVal := range expected {
-		if config[key] != expectedVal {
-			t.Errorf("LoadConfigFile()[%q] = %q, want %q", key, config[key], expectedVal)
-		}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("LoadConfigFile() = %+v, want %+v", config, expected)
 	}
 }
 
@@ -182,8 +216,100 @@ func TestLoadConfigFileNotExists(t *testing.T) {
 	if err != nil {
 		t.Fatalf("LoadConfigFile() should not error for missing file, got: %v", err)
 	}
-	if len(config) != 0 {
-		t.Errorf("LoadConfigFile() for missing file should return empty map, got: %v", config)
+	if !reflect.DeepEqual(config, &File{}) {
+		t.Errorf("LoadConfigFile() for missing file should return an empty layer, got: %+v", config)
+	}
+}
+
+// TestLoadConfigFileInclude tests merging included files below the
+// including file's own values
+func TestLoadConfigFileInclude(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	dir := t.TempDir()
+
+	writeConfig := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	basePath := filepath.Join(tmpHome, "dotfiles", "base.toml")
+	teamPath := filepath.Join(dir, "shared", "team.toml")
+	mainPath := filepath.Join(dir, "config.toml")
+	writeConfig(basePath, `terminal = "ghostty"
+remote = "upstream"
+`)
+	writeConfig(teamPath, `include = ["~/dotfiles/base.toml"]
+terminal = "iterm2"
+copy_files = [".env"]
+
+[[rules]]
+branch = "hotfix/*"
+base_branch = "release"
+`)
+	writeConfig(mainPath, `include = ["shared/team.toml"]
+copy_files = [".env", "CLAUDE.md"]
+
+[[rules]]
+branch = "docs/*"
+pre_session_cmd = ""
+`)
+
+	config, err := LoadConfigFile(mainPath)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+	if got := stringValue(config.Terminal); got != "iterm2" {
+		t.Errorf("terminal = %q, want the team value over the base value", got)
+	}
+	if got := stringValue(config.Remote); got != "upstream" {
+		t.Errorf("remote = %q, want the base value", got)
+	}
+	if expected := []string{".env", "CLAUDE.md"}; !reflect.DeepEqual(config.CopyFiles, expected) {
+		t.Errorf("copy_files = %q, want the including file's value %q", config.CopyFiles, expected)
+	}
+	if len(config.Rules) != 2 || config.Rules[0].Branch != "hotfix/*" || config.Rules[1].Branch != "docs/*" {
+		t.Errorf("rules = %+v, want the included rule, then the file's own", config.Rules)
+	}
+	if expected := []string{basePath, teamPath}; !reflect.DeepEqual(config.Included, expected) {
+		t.Errorf("Included = %q, want %q", config.Included, expected)
+	}
+}
+
+func TestLoadConfigFileIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.toml")
+	bPath := filepath.Join(dir, "b.toml")
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		contains string
+	}{
+		{"cycle", `include = ["b.toml"]`, `include = ["a.toml"]`, "include cycle"},
+		{"self include", `include = ["a.toml"]`, ``, "include cycle"},
+		{"missing file", `include = ["missing.toml"]`, ``, "not found"},
+		{"invalid included file", `include = ["b.toml"]`, `terminl = "iterm2"`, "b.toml"},
+		{"include in profile", "[profiles.x]\ninclude = [\"b.toml\"]\n", ``, "include"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(aPath, []byte(tt.a), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(bPath, []byte(tt.b), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfigFile(aPath)
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("LoadConfigFile() error = %v, want one mentioning %q", err, tt.contains)
+			}
+		})
 	}
 }
 
@@ -246,7 +372,7 @@ copy_files = ".env"
 	// Create project config
 	projectConfigPath := filepath.Join(tmpRepo, ".mxt.toml")
 	projectContent := `terminal = "iterm2"
-copy_files = ".env,.env.local,CLAUDE.md"
+copy_files = [".env", ".env.local", "notes, drafts.md"]
 `
 	if err := os.WriteFile(projectConfigPath, []byte(projectContent), 0o644); err != nil {
 		t.Fatalf("Failed to create project config file: %v", err)
@@ -261,28 +387,32 @@ copy_files = ".env,.env.local,CLAUDE.md"
 	// Expected: defaults overridden by global, overridden by project
 	// worktree_dir: global (~/global-worktrees) with tilde expanded
 	// terminal: project (iterm2)
-	// copy_files: project (.env,.env.local,CLAUDE.md)
+	// copy_files: project, with the comma inside an entry preserved
 	// pre_session_cmd: default (empty)
 	// tmux_layout: default (empty)
 
 	expectedWorktreeDir := filepath.Join(tmpHome, "global-worktrees")
-	if config["worktree_dir"] != expectedWorktreeDir {
-		t.Errorf("LoadConfig()[worktree_dir] = %q, want %q", config["worktree_dir"], expectedWorktreeDir)
+	if config.WorktreeDir != expectedWorktreeDir {
+		t.Errorf("LoadConfig().WorktreeDir = %q, want %q", config.WorktreeDir, expectedWorktreeDir)
 	}
 
-	if config["terminal"] != "iterm2" {
-		t.Errorf("LoadConfig()[terminal] = %q, want %q", config["terminal"], "iterm2")
+	if config.Terminal != "iterm2" {
+		t.Errorf("LoadConfig().Terminal = %q, want %q", config.Terminal, "iterm2")
 	}
 
-	if config["copy_files"] != ".env,.env.local,CLAUDE.md" {
-		t.Errorf("LoadConfig()[copy_files] = %q, want %q", config["copy_files"], ".env,.env.local,CLAUDE.md")
+	expectedCopyFiles := []string{".env", ".env.local", "notes, drafts.md"}
+	if !reflect.DeepEqual(config.CopyFiles, expectedCopyFiles) {
+		t.Errorf("LoadConfig().CopyFiles = %q, want %q", config.CopyFiles, expectedCopyFiles)
 	}
 
-	if config["pre_session_cmd"] != "" {
-		t.Errorf("LoadConfig()[pre_session_cmd] = %q, want empty", config["pre_session_cmd"])
+	if config.PreSessionCmd != "" {
+		t.Errorf("LoadConfig().PreSessionCmd = %q, want empty", config.PreSessionCmd)
 	}
-	if config["sandbox_tool"] != "" {
-		t.Errorf("LoadConfig()[sandbox_tool] = %q, want empty", config["sandbox_tool"])
+	if config.SandboxTool != "" {
+		t.Errorf("LoadConfig().SandboxTool = %q, want empty", config.SandboxTool)
+	}
+	if len(config.TmuxLayout) != 0 {
+		t.Errorf("LoadConfig().TmuxLayout = %q, want empty", config.TmuxLayout)
 	}
 }
 
@@ -304,14 +434,250 @@ func TestLoadConfigOnlyDefaults(t *testing.T) {
 
 	// Should have defaults with tilde expanded
 	expectedWorktreeDir := filepath.Join(tmpHome, "worktrees")
-	if config["worktree_dir"] != expectedWorktreeDir {
-		t.Errorf("LoadConfig()[worktree_dir] = %q, want %q", config["worktree_dir"], expectedWorktreeDir)
+	if config.WorktreeDir != expectedWorktreeDir {
+		t.Errorf("LoadConfig().WorktreeDir = %q, want %q", config.WorktreeDir, expectedWorktreeDir)
 	}
 
-	if config["terminal"] != "terminal" {
-		t.Errorf("LoadConfig()[terminal] = %q, want %q", config["terminal"], "terminal")
-		if config["sandbox_tool"] != "" {
-			t.Errorf("LoadConfig()[sandbox_tool] = %q, want empty", config["sandbox_tool"])
+	if config.Terminal != "terminal" {
+		t.Errorf("LoadConfig().Terminal = %q, want %q", config.Terminal, "terminal")
+		if config.SandboxTool != "" {
+			t.Errorf("LoadConfig().SandboxTool = %q, want empty", config.SandboxTool)
 		}
 	}
 }
+
+func TestLoadConfigProfile(t *testing.T) {
+	tmpHome := t.TempDir()
+	tmpRepo := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("MXT_CONFIG_DIR", "")
+
+	globalConfigDir := filepath.Join(tmpHome, ".config", "mxt")
+	if err := os.MkdirAll(globalConfigDir, 0o755); err != nil {
+		t.Fatalf("Failed to create global config dir: %v", err)
+	}
+	globalContent := `terminal = "iterm2"
+
+[profiles.backend]
+pre_session_cmd = "make deps"
+copy_files = { replace = [".env.backend"] }
+
+[profiles.docs]
+pre_session_cmd = ""
+`
+	if err := os.WriteFile(filepath.Join(globalConfigDir, "config.toml"), []byte(globalContent), 0o644); err != nil {
+		t.Fatalf("Failed to create global config file: %v", err)
+	}
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = tmpRepo
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to initialize git repo: %v", err)
+	}
+	projectContent := `default_profile = "backend"
+pre_session_cmd = "npm install"
+copy_files = [".env"]
+
+[profiles.backend]
+terminal = "ghostty"
+`
+	if err := os.WriteFile(filepath.Join(tmpRepo, ".mxt.toml"), []byte(projectContent), 0o644); err != nil {
+		t.Fatalf("Failed to create project config file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		profile       string
+		envTerminal   string
+		expectProfile string
+		expectCmd     string
+		expectTerm    string
+		expectCopy    []string
+	}{
+		{
+			name:          "default_profile merges the profile from every file",
+			expectProfile: "backend",
+			expectCmd:     "make deps",
+			expectTerm:    "ghostty",
+			expectCopy:    []string{".env.backend"},
+		},
+		{
+			name:          "explicit profile replaces default_profile",
+			profile:       "docs",
+			expectProfile: "docs",
+			expectCmd:     "",
+			expectTerm:    "iterm2",
+			expectCopy:    []string{".env"},
+		},
+		{
+			name:          "environment overrides the profile",
+			envTerminal:   "current",
+			expectProfile: "backend",
+			expectCmd:     "make deps",
+			expectTerm:    "current",
+			expectCopy:    []string{".env.backend"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MXT_TERMINAL", tt.envTerminal)
+			config, err := LoadConfigFor(tmpRepo, "", tt.profile)
+			if err != nil {
+				t.Fatalf("LoadConfigFor() error = %v", err)
+			}
+			if config.Profile != tt.expectProfile {
+				t.Errorf("Profile = %q, want %q", config.Profile, tt.expectProfile)
+			}
+			if config.PreSessionCmd != tt.expectCmd {
+				t.Errorf("PreSessionCmd = %q, want %q", config.PreSessionCmd, tt.expectCmd)
+			}
+			if config.Terminal != tt.expectTerm {
+				t.Errorf("Terminal = %q, want %q", config.Terminal, tt.expectTerm)
+			}
+			if !reflect.DeepEqual(config.CopyFiles, tt.expectCopy) {
+				t.Errorf("CopyFiles = %q, want %q", config.CopyFiles, tt.expectCopy)
+			}
+		})
+	}
+
+	_, err := LoadConfigFor(tmpRepo, "", "frontend")
+	if err == nil || !strings.Contains(err.Error(), "use backend, docs") {
+		t.Errorf("LoadConfigFor(frontend) error = %v, want unknown profile listing backend, docs", err)
+	}
+}
+
+func TestLoadConfigRules(t *testing.T) {
+	tmpHome := t.TempDir()
+	tmpRepo := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("MXT_CONFIG_DIR", "")
+
+	globalConfigDir := filepath.Join(tmpHome, ".config", "mxt")
+	if err := os.MkdirAll(globalConfigDir, 0o755); err != nil {
+		t.Fatalf("Failed to create global config dir: %v", err)
+	}
+	globalContent := `[[rules]]
+branch = "spike/*"
+tmux_layout = ["agent:"]
+pre_session_cmd = "make quick"
+`
+	if err := os.WriteFile(filepath.Join(globalConfigDir, "config.toml"), []byte(globalContent), 0o644); err != nil {
+		t.Fatalf("Failed to create global config file: %v", err)
+	}
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = tmpRepo
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to initialize git repo: %v", err)
+	}
+	projectContent := `pre_session_cmd = "npm install"
+
+[profiles.full]
+pre_session_cmd = "npm ci"
+
+[[rules]]
+branch = "hotfix/*"
+base_branch = "release"
+
+[[rules]]
+branch = "docs/*"
+pre_session_cmd = ""
+
+[[rules]]
+branch = "spike/*"
+pre_session_cmd = "npm install --offline"
+`
+	if err := os.WriteFile(filepath.Join(tmpRepo, ".mxt.toml"), []byte(projectContent), 0o644); err != nil {
+		t.Fatalf("Failed to create project config file: %v", err)
+	}
+	if err := Trust(filepath.Join(tmpRepo, ".mxt.toml")); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		branch       string
+		profile      string
+		expectBase   string
+		expectCmd    string
+		expectLayout []string
+	}{
+		{
+			name:      "no branch",
+			expectCmd: "npm install",
+		},
+		{
+			name:       "matching rule",
+			branch:     "hotfix/login",
+			expectBase: "release",
+			expectCmd:  "npm install",
+		},
+		{
+			name:   "rule clears a key",
+			branch: "docs/readme",
+		},
+		{
+			name:      "glob doesn't match across slashes",
+			branch:    "docs/api/intro",
+			expectCmd: "npm install",
+		},
+		{
+			name:         "project rule overrides global rule",
+			branch:       "spike/cache",
+			expectCmd:    "npm install --offline",
+			expectLayout: []string{"agent:"},
+		},
+		{
+			name:      "profile overrides rule",
+			branch:    "docs/readme",
+			profile:   "full",
+			expectCmd: "npm ci",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfigFor(tmpRepo, tt.branch, tt.profile)
+			if err != nil {
+				t.Fatalf("LoadConfigFor() error = %v", err)
+			}
+			if config.BaseBranch != tt.expectBase {
+				t.Errorf("BaseBranch = %q, want %q", config.BaseBranch, tt.expectBase)
+			}
+			if config.PreSessionCmd != tt.expectCmd {
+				t.Errorf("PreSessionCmd = %q, want %q", config.PreSessionCmd, tt.expectCmd)
+			}
+			if !reflect.DeepEqual(config.TmuxLayout, tt.expectLayout) {
+				t.Errorf("TmuxLayout = %q, want %q", config.TmuxLayout, tt.expectLayout)
+			}
+		})
+	}
+}
+
+func TestParseConfigProfilesAndRulesInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid default_profile", `default_profile = "my profile"`},
+		{"invalid profile name", "[profiles.\"a.b\"]\nterminal = \"iterm2\"\n"},
+		{"profile is not a table", "profiles = { backend = \"x\" }\n"},
+		{"unknown key in profile", "[profiles.backend]\nterminl = \"iterm2\"\n"},
+		{"invalid value in profile", "[profiles.backend]\nlfs_pull = \"yes\"\n"},
+		{"nested profiles", "[profiles.backend.profiles.inner]\nterminal = \"iterm2\"\n"},
+		{"rules in profile", "[[profiles.backend.rules]]\nbranch = \"a/*\"\n"},
+		{"rule without branch", "[[rules]]\nterminal = \"iterm2\"\n"},
+		{"invalid rule glob", "[[rules]]\nbranch = \"a/[\"\n"},
+		{"unknown key in rule", "[[rules]]\nbranch = \"a/*\"\nbase_brnch = \"main\"\n"},
+		{"rules is not an array of tables", "rules = \"a/*\"\n"},
+		{"nested rules", "[[rules]]\nbranch = \"a/*\"\n[[rules.rules]]\nbranch = \"b/*\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseConfig(strings.NewReader(tt.content)); err == nil {
+				t.Errorf("ParseConfig(%q) expected error", tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// flagOverrides holds the key=value settings given with --set/-c.
+var flagOverrides []string
+
+// EnvVar returns the environment variable that overrides key: MXT_ followed
+// by the upper-cased key with dots replaced by underscores, e.g.
+// MXT_WORKTREE_DIR or MXT_HOOKS_PRE_DELETE_ON_FAILURE.
+func EnvVar(key string) string {
+	return "MXT_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// SetFlagOverrides records key=value settings from --set/-c, applied above
+// every other layer by LoadConfig. Each is checked like a config file value
+// before it is accepted.
+func SetFlagOverrides(settings []string) error {
+	if _, err := parseOverrides(settings); err != nil {
+		return err
+	}
+	flagOverrides = settings
+	return nil
+}
+
+// parseOverrides builds a layer from key=value settings; later settings win.
+func parseOverrides(settings []string) (*File, error) {
+	layer := &File{}
+	for _, setting := range settings {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return nil, fmt.Errorf("--set expects key=value, got %q", setting)
+		}
+		key = strings.TrimSpace(key)
+		parsed, err := parseSetting(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --set %s: %w", key, err)
+		}
+		applySetting(layer, key, parsed)
+	}
+	return layer, nil
+}
+
+// loadEnvOverrides builds a layer from the MXT_* variable of every key.
+// Empty variables are ignored, like an unset MXT_CONFIG_DIR.
+func loadEnvOverrides() (*File, error) {
+	layer := &File{}
+	for _, key := range Keys() {
+		value := os.Getenv(EnvVar(key))
+		if value == "" {
+			continue
+		}
+		parsed, err := parseSetting(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvVar(key), err)
+		}
+		applySetting(layer, key, parsed)
+	}
+	return layer, nil
+}
+
+// applySetting copies the single key set in setting into layer. A hook's
+// command and on_failure are kept separately so both can be overridden.
+func applySetting(layer *File, key string, setting *File) {
+	stage, policy, isHook := hookKeyStage(key)
+	if !isHook {
+		*layer = *MergeConfigs(layer, setting)
+		return
+	}
+	if layer.Hooks == nil {
+		layer.Hooks = map[string]HookConfig{}
+	}
+	hook := layer.Hooks[stage]
+	if policy {
+		hook.OnFailure = setting.Hooks[stage].OnFailure
+	} else {
+		hook.Command = setting.Hooks[stage].Command
+	}
+	layer.Hooks[stage] = hook
+}
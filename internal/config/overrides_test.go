@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/hooks"
+)
+
+func TestEnvVar(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"worktree_dir", "MXT_WORKTREE_DIR"},
+		{"copy_files", "MXT_COPY_FILES"},
+		{"hooks.pre_delete", "MXT_HOOKS_PRE_DELETE"},
+		{"hooks.pre_delete.on_failure", "MXT_HOOKS_PRE_DELETE_ON_FAILURE"},
+	}
+	for _, tt := range tests {
+		if got := EnvVar(tt.key); got != tt.expected {
+			t.Errorf("EnvVar(%q) = %q, want %q", tt.key, got, tt.expected)
+		}
+	}
+}
+
+func TestParseOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings []string
+		expected *File
+		wantErr  string
+	}{
+		{
+			name:     "scalars and lists",
+			settings: []string{"terminal=current", "lfs_pull=true", `copy_files=[".env", "a,b.txt"]`, "sparse_paths=api,libs"},
+			expected: &File{
+				Terminal:    stringPtr("current"),
+				LFSPull:     boolPtr(true),
+				CopyFiles:   []string{".env", "a,b.txt"},
+				SparsePaths: []string{"api", "libs"},
+			},
+		},
+		{
+			name:     "later settings win",
+			settings: []string{"terminal=iterm2", "terminal=ghostty"},
+			expected: &File{Terminal: stringPtr("ghostty")},
+		},
+		{
+			name:     "value may contain =",
+			settings: []string{"pre_session_cmd=FOO=1 make setup"},
+			expected: &File{PreSessionCmd: stringPtr("FOO=1 make setup")},
+		},
+		{
+			name:     "hook command and policy combine",
+			settings: []string{"hooks.pre_delete.on_failure=warn", "hooks.pre_delete=bin/dump"},
+			expected: &File{Hooks: map[string]HookConfig{
+				"pre_delete": {Command: "bin/dump", OnFailure: "warn"},
+			}},
+		},
+		{
+			name:     "missing =",
+			settings: []string{"terminal"},
+			wantErr:  "expects key=value",
+		},
+		{
+			name:     "unknown key",
+			settings: []string{"worktree_path=/tmp"},
+			wantErr:  "unknown config key",
+		},
+		{
+			name:     "shell metacharacters",
+			settings: []string{"terminal=iterm2;rm -rf ~"},
+			wantErr:  "shell metacharacters",
+		},
+		{
+			name:     "invalid policy",
+			settings: []string{"hooks.pre_delete.on_failure=ignore"},
+			wantErr:  "invalid on_failure",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOverrides(tt.settings)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseOverrides() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOverrides() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseOverrides() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	t.Setenv("MXT_TERMINAL", "ghostty")
+	t.Setenv("MXT_TMUX_LAYOUT", "dev:hx;agent:")
+	t.Setenv("MXT_HOOKS_POST_CREATE_ON_FAILURE", "abort")
+	t.Setenv("MXT_REMOTE", "")
+
+	got, err := loadEnvOverrides()
+	if err != nil {
+		t.Fatalf("loadEnvOverrides() error = %v", err)
+	}
+	expected := &File{
+		Terminal:   stringPtr("ghostty"),
+		TmuxLayout: []string{"dev:hx", "agent:"},
+		Hooks: map[string]HookConfig{
+			"post_create": {OnFailure: "abort"},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("loadEnvOverrides() = %+v, want %+v", got, expected)
+	}
+
+	t.Setenv("MXT_INIT_SUBMODULES", "maybe")
+	if _, err := loadEnvOverrides(); err == nil || !strings.Contains(err.Error(), "MXT_INIT_SUBMODULES") {
+		t.Errorf("loadEnvOverrides() error = %v, want it to name MXT_INIT_SUBMODULES", err)
+	}
+}
+
+func TestLoadConfigOverrides(t *testing.T) {
+	tmpHome := t.TempDir()
+	tmpRepo := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("MXT_CONFIG_DIR", "")
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = tmpRepo
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to initialize git repo: %v", err)
+	}
+	projectContent := `terminal = "iterm2"
+remote = "upstream"
+
+[hooks]
+pre_delete = { command = "bin/dump", on_failure = "abort" }
+`
+	if err := os.WriteFile(filepath.Join(tmpRepo, ".mxt.toml"), []byte(projectContent), 0o644); err != nil {
+		t.Fatalf("Failed to create project config file: %v", err)
+	}
+	if err := Trust(filepath.Join(tmpRepo, ".mxt.toml")); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+
+	t.Setenv("MXT_TERMINAL", "ghostty")
+	t.Setenv("MXT_REMOTE", "fork")
+	t.Setenv("MXT_HOOKS_PRE_DELETE_ON_FAILURE", "warn")
+	if err := SetFlagOverrides([]string{"terminal=current"}); err != nil {
+		t.Fatalf("SetFlagOverrides() error = %v", err)
+	}
+	defer SetFlagOverrides(nil)
+
+	config, err := LoadConfig(tmpRepo)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Terminal != "current" {
+		t.Errorf("Terminal = %q, want the --set value %q", config.Terminal, "current")
+	}
+	if config.Remote != "fork" {
+		t.Errorf("Remote = %q, want the MXT_REMOTE value %q", config.Remote, "fork")
+	}
+	expectedHook := HookConfig{Command: "bin/dump", OnFailure: "warn"}
+	if config.Hooks["pre_delete"] != expectedHook {
+		t.Errorf("Hooks[pre_delete] = %+v, want %+v", config.Hooks["pre_delete"], expectedHook)
+	}
+}
+
+func TestLoadConfigIgnoresHookEnv(t *testing.T) {
+	tmpHome := t.TempDir()
+	tmpRepo := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("MXT_CONFIG_DIR", "")
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = tmpRepo
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to initialize git repo: %v", err)
+	}
+
+	expected, err := LoadConfig(tmpRepo)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	// The environment of an mxt run from inside a hook
+	ctx := hooks.Context{
+		RepoName:     "myapp",
+		RepoRoot:     tmpRepo,
+		Branch:       "feature-x",
+		BaseBranch:   "feature-x",
+		WorktreePath: filepath.Join(tmpHome, "worktrees", "myapp", "feature-x"),
+		SessionName:  "myapp_feature-x",
+	}
+	for _, variable := range ctx.Env(hooks.PostCreate) {
+		name, value, _ := strings.Cut(variable, "=")
+		t.Setenv(name, value)
+	}
+
+	config, err := LoadConfig(tmpRepo)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("LoadConfig() with the hook env = %+v, want %+v", config, expected)
+	}
+}
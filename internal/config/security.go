@@ -10,6 +10,9 @@ const dangerousChars = "`$;|&"
 
 // isCommandKey returns true if the key is a command key that should allow metacharacters
 func isCommandKey(key string) bool {
+	if strings.HasPrefix(key, "hooks.") {
+		return true
+	}
 	return key == "pre_session_cmd" || key == "tmux_layout" || key == "sandbox_tool"
 }
 
@@ -20,7 +23,7 @@ func containsMetacharacters(value string) bool {
 
 // ValidateConfigValue validates a single config key-value pair for security issues.
 // For non-command keys, it rejects values containing shell metacharacters.
-// For command keys (pre_session_cmd, tmux_layout), it allows metacharacters.
+// For command keys (pre_session_cmd, tmux_layout, sandbox_tool, hooks), it allows metacharacters.
 func ValidateConfigValue(key, value string) error {
 	// Command keys are allowed to have metacharacters
 	if isCommandKey(key) {
@@ -35,13 +38,8 @@ func ValidateConfigValue(key, value string) error {
 	return nil
 }
 
-// ValidateConfig validates all key-value pairs in a config map.
-// Returns an error if any value fails security validation.
-func ValidateConfig(config map[string]string) error {
-	for key, value := range config {
-		if err := ValidateConfigValue(key, value); err != nil {
-			return err
-		}
-	}
-	return nil
+// ValidateConfig validates every value a config layer sets, including each
+// list entry. Returns an error if any value fails security validation.
+func ValidateConfig(file *File) error {
+	return file.each(ValidateConfigValue)
 }
@@ -176,60 +176,56 @@ func TestContainsMetacharacters(t *testing.T) {
 	}
 }
 
-// TestValidateConfig tests validation of entire config map
+// TestValidateConfig tests validation of every value in a config layer
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
 		name        string
-		config      map[string]string
+		config      *File
 		shouldError bool
-		errorKey    string
 	}{
 		{
 			name: "all safe values",
-			config: map[string]string{
-				"worktree_dir": "~/worktrees",
-				"terminal":     "iterm2",
-				"copy_files":   ".env,.env.local",
+			config: &File{
+				WorktreeDir: stringPtr("~/worktrees"),
+				Terminal:    stringPtr("iterm2"),
+				CopyFiles:   []string{".env", ".env.local"},
 			},
 			shouldError: false,
 		},
 		{
 			name: "command keys with metacharacters",
-			config: map[string]string{
-				"pre_session_cmd": "npm install && npm run build",
-				"tmux_layout":     "dev:hx|lazygit",
-				"sandbox_tool":    "firejail --private && echo ready",
+			config: &File{
+				PreSessionCmd: stringPtr("npm install && npm run build"),
+				TmuxLayout:    []string{"dev:hx|lazygit"},
+				SandboxTool:   stringPtr("firejail --private && echo ready"),
+				Hooks:         map[string]HookConfig{"post_create": {Command: "bin/setup > log"}},
 			},
 			shouldError: false,
 		},
 		{
 			name: "dangerous value in worktree_dir",
-			config: map[string]string{
-				"worktree_dir": "~/worktrees`whoami`",
-				"terminal":     "iterm2",
+			config: &File{
+				WorktreeDir: stringPtr("~/worktrees`whoami`"),
+				Terminal:    stringPtr("iterm2"),
 			},
 			shouldError: true,
-			errorKey:    "worktree_dir",
 		},
 		{
 			name: "dangerous value in terminal",
-			config: map[string]string{
-				"worktree_dir": "~/worktrees",
-				"terminal":     "iterm2;rm -rf /",
+			config: &File{
+				WorktreeDir: stringPtr("~/worktrees"),
+				Terminal:    stringPtr("iterm2;rm -rf /"),
 			},
 			shouldError: true,
-			errorKey:    "terminal",
 		},
 		{
-			name: "mixed safe and dangerous",
-			config: map[string]string{
-				"worktree_dir":    "~/worktrees",
-				"terminal":        "iterm2",
-				"copy_files":      ".env|cat /etc/passwd",
-				"pre_session_cmd": "npm install",
+			name: "dangerous list entry",
+			config: &File{
+				WorktreeDir:   stringPtr("~/worktrees"),
+				CopyFiles:     []string{".env", ".env|cat /etc/passwd"},
+				PreSessionCmd: stringPtr("npm install"),
 			},
 			shouldError: true,
-			errorKey:    "copy_files",
 		},
 	}
 
@@ -0,0 +1,45 @@
+package config
+
+import "fmt"
+
+// unknownKeyError reports a key that isn't a config key, suggesting the
+// closest known key when the name looks like a typo.
+func unknownKeyError(key string) error {
+	if suggestion := suggest(key, append(Keys(), "hooks")); suggestion != "" {
+		return fmt.Errorf("unknown config key %q (did you mean %q?)", key, suggestion)
+	}
+	return fmt.Errorf("unknown config key %q", key)
+}
+
+// suggest returns the candidate closest to name, or "" when none is within
+// a third of the name's length in edits.
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/3+1
+	for _, candidate := range candidates {
+		if distance := editDistance(name, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
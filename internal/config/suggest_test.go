@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnknownKeySuggestion(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"copy_file", `did you mean "copy_files"?`},
+		{"worktree-dir", `did you mean "worktree_dir"?`},
+		{"termnal", `did you mean "terminal"?`},
+		{"hook", `did you mean "hooks"?`},
+		{"hooks.pre_delte", `did you mean "hooks.pre_delete"?`},
+		{"editor", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := CheckKey(tt.key)
+			if err == nil {
+				t.Fatalf("CheckKey(%q) expected error", tt.key)
+			}
+			if tt.expected == "" {
+				if strings.Contains(err.Error(), "did you mean") {
+					t.Errorf("CheckKey(%q) = %v, want no suggestion", tt.key, err)
+				}
+				return
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("CheckKey(%q) = %v, want %s", tt.key, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseConfigSuggestsHookStage(t *testing.T) {
+	_, err := ParseConfig(strings.NewReader("[hooks]\npost_crate = \"bin/setup\"\n"))
+	if err == nil || !strings.Contains(err.Error(), `did you mean "post_create"?`) {
+		t.Errorf("ParseConfig() error = %v, want a post_create suggestion", err)
+	}
+}
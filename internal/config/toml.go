@@ -1,37 +1,72 @@
 package config
 
 import (
-	"fmt"
-
 	"github.com/pelletier/go-toml/v2"
 )
 
-var knownConfigKeys = map[string]struct{}{
-	"worktree_dir":    {},
-	"terminal":        {},
-	"sandbox_tool":    {},
-	"copy_files":      {},
-	"pre_session_cmd": {},
-	"tmux_layout":     {},
+// EncodeConfig renders the keys a config layer sets as TOML. Lists are
+// written as arrays, so entries containing commas survive a round trip.
+func EncodeConfig(file *File) (string, error) {
+	data, err := toml.Marshal(encodeDoc(file))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
 }
 
-func validateConfigKeys(config map[string]string) error {
-	for key := range config {
-		if _, ok := knownConfigKeys[key]; !ok {
-			return fmt.Errorf("unknown config key %q", key)
+func encodeDoc(file *File) map[string]any {
+	doc := make(map[string]any)
+	if file.Include != nil {
+		doc["include"] = file.Include
+	}
+	for _, key := range stringKeys {
+		if value := *file.stringField(key); value != nil {
+			doc[key] = *value
 		}
 	}
-	return nil
-}
-
-// EncodeConfig renders the provided config map as TOML.
-func EncodeConfig(config map[string]string) (string, error) {
-	if err := validateConfigKeys(config); err != nil {
-		return "", err
+	for _, key := range boolKeys {
+		if value := *file.boolField(key); value != nil {
+			doc[key] = *value
+		}
 	}
-	data, err := toml.Marshal(config)
-	if err != nil {
-		return "", err
+	for _, key := range listKeys {
+		switch value := *file.listField(key); {
+		case value == nil:
+		case file.replaces(key):
+			doc[key] = map[string]any{"replace": value}
+		default:
+			doc[key] = value
+		}
 	}
-	return string(data), nil
+	hooks := make(map[string]any)
+	for _, stage := range HookStages {
+		hook, ok := file.Hooks[stage]
+		switch {
+		case !ok:
+		case hook.OnFailure != "":
+			hooks[stage] = map[string]string{"command": hook.Command, "on_failure": hook.OnFailure}
+		default:
+			hooks[stage] = hook.Command
+		}
+	}
+	if len(hooks) > 0 {
+		doc["hooks"] = hooks
+	}
+	if len(file.Profiles) > 0 {
+		profiles := make(map[string]any, len(file.Profiles))
+		for name, profile := range file.Profiles {
+			profiles[name] = encodeDoc(profile)
+		}
+		doc["profiles"] = profiles
+	}
+	if len(file.Rules) > 0 {
+		rules := make([]map[string]any, 0, len(file.Rules))
+		for _, rule := range file.Rules {
+			table := encodeDoc(rule.File)
+			table["branch"] = rule.Branch
+			rules = append(rules, table)
+		}
+		doc["rules"] = rules
+	}
+	return doc
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -19,34 +20,63 @@ pre_session_cmd = "echo \"hello # world\""
 		t.Fatalf("ParseConfig() error = %v", err)
 	}
 
-	expected := map[string]string{
-		"worktree_dir":    "/home/user/worktrees",
-		"terminal":        "iterm2",
-		"sandbox_tool":    "firejail --private",
-		"copy_files":      ".env,.env.local",
-		"pre_session_cmd": "echo \"hello # world\"",
+	expected := &File{
+		WorktreeDir:   stringPtr("/home/user/worktrees"),
+		Terminal:      stringPtr("iterm2"),
+		SandboxTool:   stringPtr("firejail --private"),
+		CopyFiles:     []string{".env", ".env.local"},
+		PreSessionCmd: stringPtr("echo \"hello # world\""),
 	}
-
-	for key, expectedVal := range expected {
-		if config[key] != expectedVal {
-			t.Errorf("ParseConfig()[%q] = %q, want %q", key, config[key], expectedVal)
-		}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("ParseConfig() = %+v, want %+v", config, expected)
 	}
 }
 
-func TestParseConfigArrayValues(t *testing.T) {
-	input := `copy_files = [".env", ".env.local"]
-	tmux_layout = ["dev:hx|lazygit", "server:bin/server", "agent:"]`
-	config, err := ParseConfig(strings.NewReader(input))
+func TestParseConfigListValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		key      string
+		expected []string
+	}{
+		{"copy_files string", `copy_files = ".env, .env.local,"`, "copy_files", []string{".env", ".env.local"}},
+		{"copy_files array keeps commas", `copy_files = [".env", "notes,draft.md"]`, "copy_files", []string{".env", "notes,draft.md"}},
+		{"copy_ignored array", `copy_ignored = ["**/.env*", "!node_modules/**"]`, "copy_ignored", []string{"**/.env*", "!node_modules/**"}},
+		{"sparse_paths array", `sparse_paths = ["packages/api", "libs/shared"]`, "sparse_paths", []string{"packages/api", "libs/shared"}},
+		{"empty string clears", `copy_files = ""`, "copy_files", []string{}},
+		{"tmux_layout comma separated", `tmux_layout = "dev:hx|lazygit,server:bin/server,agent:"`, "tmux_layout", []string{"dev:hx|lazygit", "server:bin/server", "agent:"}},
+		{"tmux_layout mixed separators", `tmux_layout = "dev:hx;;server:bin/server, agent:"`, "tmux_layout", []string{"dev:hx", "server:bin/server", "agent:"}},
+		{"tmux_layout multi-line string", "tmux_layout = \"\"\"\n  dev:hx|lazygit\n  server:bin/server\n  agent:\n  \"\"\"", "tmux_layout", []string{"dev:hx|lazygit", "server:bin/server", "agent:"}},
+		{"tmux_layout array keeps one window per entry", `tmux_layout = ["dev:hx|lazygit", "server:bin/server --hosts a,b", "agent:"]`, "tmux_layout", []string{"dev:hx|lazygit", "server:bin/server --hosts a,b", "agent:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseConfig() error = %v", err)
+			}
+			if got := *config.listField(tt.key); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseConfig()[%s] = %q, want %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ParseConfig(strings.NewReader(`copy_files = [".env", 1]`)); err == nil {
+		t.Error("ParseConfig() expected error for non-string array value")
+	}
+
+	config, err := ParseConfig(strings.NewReader(`copy_ignored = { replace = [] }`))
 	if err != nil {
 		t.Fatalf("ParseConfig() error = %v", err)
 	}
-	if config["copy_files"] != ".env,.env.local" {
-		t.Errorf("ParseConfig()[copy_files] = %q, want %q", config["copy_files"], ".env,.env.local")
+	expected := &File{CopyIgnored: []string{}, ReplaceLists: []string{"copy_ignored"}}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("ParseConfig() = %+v, want %+v", config, expected)
 	}
-	expectedLayout := "dev:hx|lazygit;server:bin/server;agent:"
-	if config["tmux_layout"] != expectedLayout {
-		t.Errorf("ParseConfig()[tmux_layout] = %q, want %q", config["tmux_layout"], expectedLayout)
+	for _, input := range []string{`copy_files = { append = [".env"] }`, `tmux_layout = { replace = ["agent:"] }`} {
+		if _, err := ParseConfig(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseConfig(%q) expected error", input)
+		}
 	}
 }
 
@@ -57,37 +87,170 @@ func TestParseConfigUnknownKey(t *testing.T) {
 	}
 }
 
-func TestParseConfigTmuxLayoutMultiline(t *testing.T) {
-	input := `tmux_layout = """
-  dev:hx|lazygit
-  server:bin/server
-  agent:
-  """`
+func TestParseConfigEmptyInput(t *testing.T) {
+	config, err := ParseConfig(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(config, &File{}) {
+		t.Errorf("ParseConfig() = %+v, want an empty layer", config)
+	}
+}
+
+func TestEncodeConfigRoundTrip(t *testing.T) {
+	file := &File{
+		Include:        []string{"~/dotfiles/mxt/team.toml"},
+		WorktreeDir:    stringPtr("~/worktrees"),
+		CopyFiles:      []string{".env", "notes,draft.md"},
+		TmuxLayout:     []string{"dev:hx|lazygit", "server:bin/server --hosts a,b"},
+		FetchBeforeNew: boolPtr(true),
+		Profiles: map[string]*File{
+			"docs": {PreSessionCmd: stringPtr(""), CopyIgnored: []string{}, ReplaceLists: []string{"copy_ignored"}},
+		},
+		Rules: []Rule{
+			{Branch: "hotfix/*", File: &File{BaseBranch: stringPtr("release")}},
+			{Branch: "spike-*", File: &File{TmuxLayout: []string{"agent:"}}},
+		},
+	}
+	encoded, err := EncodeConfig(file)
+	if err != nil {
+		t.Fatalf("EncodeConfig() error = %v", err)
+	}
+	if !strings.Contains(encoded, "fetch_before_new = true") {
+		t.Errorf("EncodeConfig() = %q, want a TOML boolean for fetch_before_new", encoded)
+	}
+	roundTrip, err := ParseConfig(strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("ParseConfig(EncodeConfig()) error = %v", err)
+	}
+	if !reflect.DeepEqual(roundTrip, file) {
+		t.Errorf("round trip = %+v, want %+v", roundTrip, file)
+	}
+}
 
+func TestParseConfigHooks(t *testing.T) {
+	input := `[hooks]
+post_create = "bin/setup-db"
+pre_delete = { command = "bin/dump-db > dump.sql", on_failure = "warn" }
+`
 	config, err := ParseConfig(strings.NewReader(input))
 	if err != nil {
 		t.Fatalf("ParseConfig() error = %v", err)
 	}
 
-	expected := "dev:hx|lazygit;server:bin/server;agent:"
-	if config["tmux_layout"] != expected {
-		t.Errorf("ParseConfig()[tmux_layout] = %q, want %q", config["tmux_layout"], expected)
+	expected := map[string]HookConfig{
+		"post_create": {Command: "bin/setup-db"},
+		"pre_delete":  {Command: "bin/dump-db > dump.sql", OnFailure: "warn"},
+	}
+	if !reflect.DeepEqual(config.Hooks, expected) {
+		t.Errorf("ParseConfig().Hooks = %+v, want %+v", config.Hooks, expected)
+	}
+	if err := ValidateConfig(config); err != nil {
+		t.Errorf("ValidateConfig() error = %v, want hooks to allow shell metacharacters", err)
+	}
+
+	encoded, err := EncodeConfig(config)
+	if err != nil {
+		t.Fatalf("EncodeConfig() error = %v", err)
+	}
+	roundTrip, err := ParseConfig(strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("ParseConfig(EncodeConfig()) error = %v", err)
+	}
+	if !reflect.DeepEqual(roundTrip.Hooks, expected) {
+		t.Errorf("round trip hooks = %+v, want %+v", roundTrip.Hooks, expected)
+	}
+}
+
+func TestParseConfigHooksRejectsInvalid(t *testing.T) {
+	inputs := []string{
+		"[hooks]\npost_merge = \"echo hi\"",
+		"[hooks]\npost_create = { command = \"echo hi\", on_failure = \"ignore\" }",
+		"[hooks]\npost_create = { cmd = \"echo hi\" }",
+		"hooks = \"echo hi\"",
+	}
+	for _, input := range inputs {
+		if _, err := ParseConfig(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseConfig(%q) expected error", input)
+		}
+	}
+}
+
+func TestParseConfigPreSessionMode(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader("pre_session_mode = \"window\"\npre_session_timeout = \"90s\""))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if stringValue(cfg.PreSessionMode) != "window" {
+		t.Errorf("pre_session_mode = %q, want %q", stringValue(cfg.PreSessionMode), "window")
+	}
+	if stringValue(cfg.PreSessionTimeout) != "90s" {
+		t.Errorf("pre_session_timeout = %q, want %q", stringValue(cfg.PreSessionTimeout), "90s")
+	}
+
+	inputs := []string{
+		"pre_session_mode = \"background\"",
+		"pre_session_timeout = \"ten minutes\"",
+	}
+	for _, input := range inputs {
+		if _, err := ParseConfig(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseConfig(%q) expected error", input)
+		}
 	}
 }
 
-func TestParseConfigEmptyInput(t *testing.T) {
-	config, err := ParseConfig(strings.NewReader(""))
+func TestParseConfigFetchSettings(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader("fetch_before_new = true\nremote = \"upstream\"\nbase_branch = \"develop\""))
 	if err != nil {
 		t.Fatalf("ParseConfig() error = %v", err)
 	}
-	if len(config) != 0 {
-		t.Errorf("ParseConfig() = %v, want empty map", config)
+	expected := &File{FetchBeforeNew: boolPtr(true), Remote: stringPtr("upstream"), BaseBranch: stringPtr("develop")}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("ParseConfig() = %+v, want %+v", cfg, expected)
+	}
+
+	inputs := []string{
+		"fetch_before_new = \"yes\"",
+		"init_submodules = 1",
+		"remote = \"--upload-pack=evil\"",
+		"base_branch = \"main branch\"",
+	}
+	for _, input := range inputs {
+		if _, err := ParseConfig(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseConfig(%q) expected error", input)
+		}
 	}
 }
 
-func TestEncodeConfigRejectsUnknownKey(t *testing.T) {
-	_, err := EncodeConfig(map[string]string{"unknown": "value"})
-	if err == nil {
-		t.Fatal("EncodeConfig() expected error for unknown key")
+func TestParseConfigSubmoduleSettings(t *testing.T) {
+	cfg, err := ParseConfig(strings.NewReader("init_submodules = true\nlfs_pull = false"))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if !boolValue(cfg.InitSubmodules) || cfg.LFSPull == nil || *cfg.LFSPull {
+		t.Errorf("ParseConfig() = %+v, want init_submodules=true lfs_pull=false", cfg)
+	}
+}
+
+func TestConvertLegacyConfig(t *testing.T) {
+	file, err := ConvertLegacyConfig(map[string]string{
+		"copy_files":       ".env,.env.local",
+		"tmux_layout":      "dev:hx|lazygit;agent:",
+		"fetch_before_new": "true",
+	})
+	if err != nil {
+		t.Fatalf("ConvertLegacyConfig() error = %v", err)
+	}
+	expected := &File{
+		CopyFiles:      []string{".env", ".env.local"},
+		TmuxLayout:     []string{"dev:hx|lazygit", "agent:"},
+		FetchBeforeNew: boolPtr(true),
+	}
+	if !reflect.DeepEqual(file, expected) {
+		t.Errorf("ConvertLegacyConfig() = %+v, want %+v", file, expected)
+	}
+
+	if _, err := ConvertLegacyConfig(map[string]string{"unknown": "value"}); err == nil {
+		t.Error("ConvertLegacyConfig() expected error for unknown key")
 	}
 }
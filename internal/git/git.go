@@ -2,11 +2,13 @@
 package git
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -79,44 +81,70 @@ func GetRepoName() (string, error) {
 	return filepath.Base(root), nil
 }
 
-// GetMainBranch detects and returns the name of the main branch.
+// GetMainBranch detects and returns the name of the main branch on origin.
+// See GetDefaultBranch.
+func GetMainBranch() string {
+	return GetDefaultBranch("origin")
+}
+
+// GetDefaultBranch detects and returns the name of the default branch for remote.
 // Algorithm:
-//  1. Try: git symbolic-ref refs/remotes/origin/HEAD → extract last component
-//  2. If fails, check if "main" exists
-//  3. If not, check if "master" exists
+//  1. Try: git symbolic-ref refs/remotes/<remote>/HEAD → strip refs/remotes/<remote>/
+//  2. If fails, check if "main" exists locally or on remote
+//  3. If not, check if "master" exists locally or on remote
 //  4. Fallback: return "main"
-func GetMainBranch() string {
-	// Try to get the default branch from origin/HEAD
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
+func GetDefaultBranch(remote string) string {
+	// Try to get the default branch from <remote>/HEAD
+	remotePrefix := "refs/remotes/" + remote + "/"
+	cmd := exec.Command("git", "symbolic-ref", remotePrefix+"HEAD")
 	output, err := cmd.Output()
 	if err == nil {
 		// Output format: refs/remotes/origin/main
-		ref := strings.TrimSpace(string(output))
-		parts := strings.Split(ref, "/")
-		if len(parts) > 0 {
-			branch := parts[len(parts)-1]
-			if branch != "" {
-				return branch
-			}
+		branch := strings.TrimPrefix(strings.TrimSpace(string(output)), remotePrefix)
+		if branch != "" {
+			return branch
 		}
 	}
 
-	// Check if "main" branch exists
-	cmd = exec.Command("git", "show-ref", "--verify", "refs/heads/main")
-	if err := cmd.Run(); err == nil {
-		return "main"
-	}
-
-	// Check if "master" branch exists
-	cmd = exec.Command("git", "show-ref", "--verify", "refs/heads/master")
-	if err := cmd.Run(); err == nil {
-		return "master"
+	// Check "main", then "master" (local branch first, then remote-tracking)
+	for _, branch := range []string{"main", "master"} {
+		if RefExists("refs/heads/"+branch) || RefExists(remotePrefix+branch) {
+			return branch
+		}
 	}
 
 	// Fallback to "main"
 	return "main"
 }
 
+// RefExists reports whether a fully qualified ref (e.g. refs/heads/main) exists.
+// Uses: git show-ref --verify --quiet <ref>
+func RefExists(ref string) bool {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", ref)
+	return cmd.Run() == nil
+}
+
+// ValidBranchName reports whether git accepts name as a branch name.
+// Uses: git check-ref-format --branch <name>
+func ValidBranchName(name string) bool {
+	cmd := exec.Command("git", "check-ref-format", "--branch", name)
+	cmd.Stdout = io.Discard
+	return cmd.Run() == nil
+}
+
+// Fetch updates the remote-tracking ref for branch from remote.
+// Git's progress output is passed through to stderr.
+// Uses: git fetch <remote> <branch>
+func Fetch(remote, branch string) error {
+	cmd := exec.Command("git", "fetch", remote, branch)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git fetch %s %s failed: %w", remote, branch, err)
+	}
+	return nil
+}
+
 // IsInsideWorkTree checks if the current directory is inside a git work tree.
 // Uses: git rev-parse --is-inside-work-tree
 func IsInsideWorkTree() bool {
@@ -136,3 +164,261 @@ func DeleteBranch(branch string) error {
 	cmd.Stderr = io.Discard
 	return cmd.Run()
 }
+
+// RenameBranch renames a local branch, failing if newName already exists.
+// Uses: git branch -m <old> <new>
+func RenameBranch(oldName, newName string) error {
+	cmd := exec.Command("git", "branch", "-m", oldName, newName)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git branch -m failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Worktree is one entry of git worktree list --porcelain.
+type Worktree struct {
+	Path           string
+	Head           string // Commit checked out; empty for a bare repository
+	Branch         string // Short branch name; empty when detached or bare
+	Bare           bool
+	Detached       bool
+	Locked         bool
+	LockReason     string // May be empty even when Locked
+	Prunable       bool   // The worktree directory is gone; git worktree prune would remove it
+	PrunableReason string
+}
+
+// ListWorktrees returns every worktree of the repository. The main worktree
+// is always first.
+// Uses: git worktree list --porcelain
+func ListWorktrees() ([]Worktree, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree list failed: %w", err)
+	}
+	return ParseWorktrees(string(output)), nil
+}
+
+// FindWorktree returns the worktree checked out at path, comparing absolute
+// paths with symlinks resolved. It works even when the worktree's directory
+// is missing, as git still lists it. The bool is false if path isn't a
+// worktree of the repository.
+// Uses: git worktree list --porcelain
+func FindWorktree(path string) (Worktree, bool, error) {
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return Worktree{}, false, err
+	}
+	path = resolvePath(path)
+	for _, wt := range worktrees {
+		if resolvePath(wt.Path) == path {
+			return wt, true, nil
+		}
+	}
+	return Worktree{}, false, nil
+}
+
+// resolvePath makes path absolute and resolves symlinks where it exists.
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
+// ParseWorktrees parses git worktree list --porcelain output. Entries start
+// with a "worktree <path>" line and are separated by blank lines; "locked" and
+// "prunable" may be followed by a reason.
+func ParseWorktrees(output string) []Worktree {
+	var worktrees []Worktree
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			worktrees = append(worktrees, Worktree{Path: path})
+			continue
+		}
+		if line == "" || len(worktrees) == 0 {
+			continue
+		}
+
+		wt := &worktrees[len(worktrees)-1]
+		attribute, value, _ := strings.Cut(line, " ")
+		switch attribute {
+		case "HEAD":
+			wt.Head = value
+		case "branch":
+			wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			wt.Bare = true
+		case "detached":
+			wt.Detached = true
+		case "locked":
+			wt.Locked = true
+			wt.LockReason = value
+		case "prunable":
+			wt.Prunable = true
+			wt.PrunableReason = value
+		}
+	}
+	return worktrees
+}
+
+// GetMainWorktree returns the path of the main checkout, even when run from a
+// linked worktree. git lists the main worktree first.
+// Uses: git worktree list --porcelain
+func GetMainWorktree() (string, error) {
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+	if len(worktrees) == 0 {
+		return "", fmt.Errorf("no worktrees found")
+	}
+	return worktrees[0].Path, nil
+}
+
+// FindBranchWorktree returns the path of the worktree that has branch checked
+// out, if any.
+// Uses: git worktree list --porcelain
+func FindBranchWorktree(branch string) (string, bool) {
+	worktrees, err := ListWorktrees()
+	if err != nil {
+		return "", false
+	}
+	for _, wt := range worktrees {
+		if branch != "" && wt.Branch == branch {
+			return wt.Path, true
+		}
+	}
+	return "", false
+}
+
+// ResolveMainRepo returns the main checkout of the repository that the linked
+// worktree at worktreePath belongs to, without running git. It follows the
+// worktree's .git file ("gitdir: <repo>/.git/worktrees/<name>") and the
+// commondir file in that directory. For a bare repository, the repository
+// directory itself is returned.
+func ResolveMainRepo(worktreePath string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(worktreePath, ".git"))
+	if err != nil {
+		return "", fmt.Errorf("not a linked worktree: %w", err)
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("not a linked worktree: unexpected .git file in %s", worktreePath)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(worktreePath, gitDir)
+	}
+
+	commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return "", fmt.Errorf("repository for %s not found: %w", worktreePath, err)
+	}
+	repoDir := strings.TrimSpace(string(commonDir))
+	if !filepath.IsAbs(repoDir) {
+		repoDir = filepath.Join(gitDir, repoDir)
+	}
+	repoDir = filepath.Clean(repoDir)
+
+	if filepath.Base(repoDir) == ".git" {
+		return filepath.Dir(repoDir), nil
+	}
+	return repoDir, nil
+}
+
+// GetCurrentBranch returns the branch checked out in dir, or "" when HEAD is detached.
+// Uses: git symbolic-ref --quiet --short HEAD
+func GetCurrentBranch(dir string) string {
+	cmd := exec.Command("git", "-C", dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// StatusLines returns the porcelain status of the checkout at dir: one line per
+// modified, staged or untracked (but not ignored) path. Empty means clean.
+// Uses: git status --porcelain
+func StatusLines(dir string) ([]string, error) {
+	cmd := exec.Command("git", "-C", dir, "status", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed in %s: %w", dir, err)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// ResolveCommit returns the commit hash a revision points to.
+// Uses: git rev-parse --verify <rev>^{commit}
+func ResolveCommit(dir, rev string) (string, error) {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// MergeBase returns the best common ancestor commit of two revisions.
+// Uses: git merge-base <a> <b>
+func MergeBase(a, b string) (string, error) {
+	cmd := exec.Command("git", "merge-base", a, b)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no common ancestor between %s and %s", a, b)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CountCommits returns the number of commits reachable from head but not from base.
+// Uses: git rev-list --count <base>..<head>
+func CountCommits(base, head string) (int, error) {
+	cmd := exec.Command("git", "rev-list", "--count", base+".."+head)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list failed: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// UpdateBranch points branch at newCommit, but only if it still points at
+// oldCommit (so a concurrent update is never overwritten).
+// Uses: git update-ref refs/heads/<branch> <new> <old>
+func UpdateBranch(branch, newCommit, oldCommit string) error {
+	cmd := exec.Command("git", "update-ref", "-m", "mxt finish", "refs/heads/"+branch, newCommit, oldCommit)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update %s: %s", branch, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ListIgnoredFiles returns the untracked files in repoRoot that are ignored by
+// git's standard exclude rules (.gitignore, .git/info/exclude, core.excludesFile).
+// Paths are relative to repoRoot and slash-separated.
+// Uses: git ls-files -z --others --ignored --exclude-standard
+func ListIgnoredFiles(repoRoot string) ([]string, error) {
+	cmd := exec.Command("git", "-C", repoRoot, "ls-files", "-z", "--others", "--ignored", "--exclude-standard")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
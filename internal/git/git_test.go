@@ -1,6 +1,7 @@
 package git
 
 import (
+	"os/exec"
 	"path/filepath"
 	"testing"
 )
@@ -408,3 +409,138 @@ func TestIsInsideWorkTree(t *testing.T) {
 		t.Error("IsInsideWorkTree() = false, want true (tests should run inside git repo)")
 	}
 }
+
+// TestGetDefaultBranch tests default branch detection for a configurable remote
+func TestGetDefaultBranch(t *testing.T) {
+	repo := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q", "-b", "master")
+	runGit("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	runGit("update-ref", "refs/remotes/upstream/release/v2", "HEAD")
+	runGit("symbolic-ref", "refs/remotes/upstream/HEAD", "refs/remotes/upstream/release/v2")
+	runGit("update-ref", "refs/remotes/fork/main", "HEAD")
+	t.Chdir(repo)
+
+	tests := []struct {
+		remote   string
+		expected string
+	}{
+		{remote: "upstream", expected: "release/v2"}, // <remote>/HEAD, keeping slashes
+		{remote: "fork", expected: "main"},           // remote-tracking main
+		{remote: "origin", expected: "master"},       // local master
+	}
+	for _, tt := range tests {
+		if branch := GetDefaultBranch(tt.remote); branch != tt.expected {
+			t.Errorf("GetDefaultBranch(%q) = %q, want %q", tt.remote, branch, tt.expected)
+		}
+	}
+
+	if !RefExists("refs/remotes/fork/main") {
+		t.Error("RefExists(refs/remotes/fork/main) = false, want true")
+	}
+	if RefExists("refs/heads/main") {
+		t.Error("RefExists(refs/heads/main) = true, want false")
+	}
+}
+
+// TestResolveMainRepo tests resolving a linked worktree back to its main checkout
+func TestResolveMainRepo(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "app")
+	linked := filepath.Join(base, "wt", "app", "feature")
+	runGit := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	runGit("init", "-q", repo)
+	runGit("-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init")
+	runGit("-C", repo, "worktree", "add", "-q", "-b", "feature", linked)
+
+	mainRepo, err := ResolveMainRepo(linked)
+	if err != nil {
+		t.Fatalf("ResolveMainRepo() error = %v", err)
+	}
+	expected, _ := filepath.EvalSymlinks(repo)
+	if resolved, _ := filepath.EvalSymlinks(mainRepo); resolved != expected {
+		t.Errorf("ResolveMainRepo() = %q, want %q", mainRepo, repo)
+	}
+
+	if _, err := ResolveMainRepo(repo); err == nil {
+		t.Error("ResolveMainRepo(main checkout) expected error")
+	}
+	if _, err := ResolveMainRepo(filepath.Join(base, "missing")); err == nil {
+		t.Error("ResolveMainRepo(missing) expected error")
+	}
+}
+
+// TestParseWorktrees tests parsing git worktree list --porcelain output
+func TestParseWorktrees(t *testing.T) {
+	output := "worktree /src/app\n" +
+		"HEAD 1111111111111111111111111111111111111111\n" +
+		"branch refs/heads/main\n" +
+		"\n" +
+		"worktree /wt/app/feature-auth\n" +
+		"HEAD 2222222222222222222222222222222222222222\n" +
+		"branch refs/heads/feature/auth\n" +
+		"locked release freeze\n" +
+		"\n" +
+		"worktree /wt/app/scratch\n" +
+		"HEAD 3333333333333333333333333333333333333333\n" +
+		"detached\n" +
+		"locked\n" +
+		"\n" +
+		"worktree /wt/app/gone\n" +
+		"HEAD 4444444444444444444444444444444444444444\n" +
+		"branch refs/heads/gone\n" +
+		"prunable gitdir file points to non-existent location\n" +
+		"\n"
+
+	expected := []Worktree{
+		{Path: "/src/app", Head: "1111111111111111111111111111111111111111", Branch: "main"},
+		{Path: "/wt/app/feature-auth", Head: "2222222222222222222222222222222222222222", Branch: "feature/auth", Locked: true, LockReason: "release freeze"},
+		{Path: "/wt/app/scratch", Head: "3333333333333333333333333333333333333333", Detached: true, Locked: true},
+		{Path: "/wt/app/gone", Head: "4444444444444444444444444444444444444444", Branch: "gone", Prunable: true, PrunableReason: "gitdir file points to non-existent location"},
+	}
+	worktrees := ParseWorktrees(output)
+	if len(worktrees) != len(expected) {
+		t.Fatalf("ParseWorktrees() returned %d worktrees, want %d", len(worktrees), len(expected))
+	}
+	for i := range expected {
+		if worktrees[i] != expected[i] {
+			t.Errorf("ParseWorktrees()[%d] = %+v, want %+v", i, worktrees[i], expected[i])
+		}
+	}
+
+	bare := ParseWorktrees("worktree /srv/app.git\nbare\n")
+	if len(bare) != 1 || !bare[0].Bare || bare[0].Branch != "" {
+		t.Errorf("ParseWorktrees(bare) = %+v, want one bare worktree", bare)
+	}
+}
+
+func TestValidBranchName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"feature/auth", true},
+		{"fix-123", true},
+		{"has space", false},
+		{"double..dot", false},
+		{"ends.lock", false},
+		{"trailing/", false},
+	}
+	for _, tt := range tests {
+		if got := ValidBranchName(tt.name); got != tt.expected {
+			t.Errorf("ValidBranchName(%q) = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
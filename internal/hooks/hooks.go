@@ -0,0 +1,135 @@
+// Package hooks runs user-configured lifecycle hook commands.
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/gkarolyi/mxt/internal/sandbox"
+	"github.com/gkarolyi/mxt/internal/ui"
+)
+
+// Lifecycle stages at which hooks can run.
+const (
+	PostCreate    = "post_create"     // After the worktree is created and files are copied
+	PreDelete     = "pre_delete"      // Before the session is killed and the worktree removed
+	PostDelete    = "post_delete"     // After the worktree and branch are removed
+	OnSessionOpen = "on_session_open" // After a tmux session is created
+)
+
+// Failure policies controlling what happens when a hook exits non-zero.
+const (
+	PolicyAbort  = "abort"  // Stop the command with an error
+	PolicyWarn   = "warn"   // Print a warning and continue
+	PolicyPrompt = "prompt" // Ask whether to continue (aborts when not interactive)
+)
+
+// DefaultPolicy returns the failure policy used when a hook does not set on_failure.
+//
+//   - post_create:     prompt (same as pre_session_cmd)
+//   - pre_delete:      abort  (don't delete if e.g. a database dump failed)
+//   - post_delete:     warn   (nothing left to protect)
+//   - on_session_open: warn   (the session already exists)
+func DefaultPolicy(stage string) string {
+	switch stage {
+	case PostCreate:
+		return PolicyPrompt
+	case PreDelete:
+		return PolicyAbort
+	default:
+		return PolicyWarn
+	}
+}
+
+// Context describes the worktree a hook runs for. Each non-empty field is
+// exported to the hook as an MXT_HOOK_* environment variable, a prefix no
+// config key override uses (those are MXT_<KEY>), so an mxt run from inside
+// a hook loads the same config.
+type Context struct {
+	RepoName     string // MXT_HOOK_REPO_NAME
+	RepoRoot     string // MXT_HOOK_REPO_ROOT
+	Branch       string // MXT_HOOK_BRANCH
+	BaseBranch   string // MXT_HOOK_BASE_BRANCH
+	WorktreePath string // MXT_HOOK_WORKTREE_PATH
+	SessionName  string // MXT_HOOK_SESSION_NAME
+}
+
+// Env returns the MXT_HOOK* environment variables for a hook at the given stage.
+func (c Context) Env(stage string) []string {
+	env := []string{"MXT_HOOK=" + stage}
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{"MXT_HOOK_REPO_NAME", c.RepoName},
+		{"MXT_HOOK_REPO_ROOT", c.RepoRoot},
+		{"MXT_HOOK_BRANCH", c.Branch},
+		{"MXT_HOOK_BASE_BRANCH", c.BaseBranch},
+		{"MXT_HOOK_WORKTREE_PATH", c.WorktreePath},
+		{"MXT_HOOK_SESSION_NAME", c.SessionName},
+	}
+	for _, v := range vars {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+	return env
+}
+
+// HookError reports a hook command that exited non-zero.
+type HookError struct {
+	Stage    string
+	ExitCode int
+}
+
+func (e HookError) Error() string {
+	return fmt.Sprintf("%s hook failed (exit code: %d)", e.Stage, e.ExitCode)
+}
+
+// Run executes a hook command via `sh -c` in dir, wrapped by sandboxTool when set.
+// The hook inherits mxt's environment plus the MXT_HOOK* variables from ctx.
+func Run(stage, command, dir string, ctx Context, sandboxTool string) error {
+	ui.Info(fmt.Sprintf("Running %s hook...", stage))
+	fmt.Printf("  %s\n", ui.DimText(command))
+
+	cmd := sandbox.Command(sandboxTool, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), ctx.Env(stage)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return HookError{Stage: stage, ExitCode: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("%s hook failed: %w", stage, err)
+	}
+
+	ui.Success(fmt.Sprintf("%s hook completed", stage))
+	return nil
+}
+
+// HandleFailure applies a failure policy to a hook error.
+// It returns nil when the caller should continue, or an error to abort with.
+// For PolicyPrompt, confirm is called to ask the user; pass nil when not
+// interactive so the prompt policy falls back to aborting.
+func HandleFailure(stage, policy string, hookErr error, confirm func() bool) error {
+	if hookErr == nil {
+		return nil
+	}
+	if policy == "" {
+		policy = DefaultPolicy(stage)
+	}
+
+	ui.Warn(hookErr.Error())
+	switch policy {
+	case PolicyWarn:
+		return nil
+	case PolicyPrompt:
+		if confirm != nil && confirm() {
+			return nil
+		}
+	}
+	return fmt.Errorf("Aborted due to %s hook failure", stage)
+}
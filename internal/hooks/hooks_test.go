@@ -0,0 +1,91 @@
+package hooks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContextEnvSkipsEmptyValues(t *testing.T) {
+	ctx := Context{
+		RepoName:     "myapp",
+		Branch:       "feature/auth",
+		WorktreePath: "/wt/myapp/feature-auth",
+	}
+
+	expected := []string{
+		"MXT_HOOK=post_create",
+		"MXT_HOOK_REPO_NAME=myapp",
+		"MXT_HOOK_BRANCH=feature/auth",
+		"MXT_HOOK_WORKTREE_PATH=/wt/myapp/feature-auth",
+	}
+	if env := ctx.Env(PostCreate); !reflect.DeepEqual(env, expected) {
+		t.Errorf("Env() = %v, want %v", env, expected)
+	}
+}
+
+func TestRunExportsEnvAndReportsExitCode(t *testing.T) {
+	dir := t.TempDir()
+	ctx := Context{Branch: "feature-auth"}
+
+	if err := Run(PostCreate, `printf '%s:%s' "$MXT_HOOK" "$MXT_HOOK_BRANCH" > out.txt`, dir, ctx, ""); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("failed to read hook output: %v", err)
+	}
+	if string(content) != "post_create:feature-auth" {
+		t.Errorf("hook output = %q, want %q", string(content), "post_create:feature-auth")
+	}
+
+	err = Run(PreDelete, "exit 3", dir, ctx, "")
+	var hookErr HookError
+	if !errors.As(err, &hookErr) {
+		t.Fatalf("Run() error = %v, want HookError", err)
+	}
+	if hookErr.ExitCode != 3 || hookErr.Stage != PreDelete {
+		t.Errorf("Run() error = %#v, want stage %q exit 3", hookErr, PreDelete)
+	}
+}
+
+func TestHandleFailurePolicies(t *testing.T) {
+	hookErr := HookError{Stage: PostCreate, ExitCode: 1}
+	confirmYes := func() bool { return true }
+	confirmNo := func() bool { return false }
+
+	tests := []struct {
+		name      string
+		stage     string
+		policy    string
+		confirm   func() bool
+		wantAbort bool
+	}{
+		{name: "warn continues", stage: PostCreate, policy: PolicyWarn, wantAbort: false},
+		{name: "abort stops", stage: PostCreate, policy: PolicyAbort, wantAbort: true},
+		{name: "prompt confirmed", stage: PostCreate, policy: PolicyPrompt, confirm: confirmYes, wantAbort: false},
+		{name: "prompt declined", stage: PostCreate, policy: PolicyPrompt, confirm: confirmNo, wantAbort: true},
+		{name: "prompt non-interactive aborts", stage: PostCreate, policy: PolicyPrompt, confirm: nil, wantAbort: true},
+		{name: "pre_delete defaults to abort", stage: PreDelete, policy: "", wantAbort: true},
+		{name: "post_delete defaults to warn", stage: PostDelete, policy: "", wantAbort: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := HandleFailure(tt.stage, tt.policy, hookErr, tt.confirm)
+			if (err != nil) != tt.wantAbort {
+				t.Fatalf("HandleFailure() error = %v, wantAbort %v", err, tt.wantAbort)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.stage) {
+				t.Errorf("HandleFailure() error = %q, want stage name", err.Error())
+			}
+		})
+	}
+
+	if err := HandleFailure(PreDelete, PolicyAbort, nil, nil); err != nil {
+		t.Errorf("HandleFailure(nil) error = %v, want nil", err)
+	}
+}
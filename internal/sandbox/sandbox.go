@@ -1,6 +1,7 @@
 package sandbox
 
 import (
+	"context"
 	"os/exec"
 	"strings"
 )
@@ -16,6 +17,16 @@ func Command(sandboxTool string, command string, args ...string) *exec.Cmd {
 	return exec.Command("sh", "-c", full)
 }
 
+// CommandContext is like Command but the returned command is killed when ctx is done.
+func CommandContext(ctx context.Context, sandboxTool string, command string, args ...string) *exec.Cmd {
+	if strings.TrimSpace(sandboxTool) == "" {
+		return exec.CommandContext(ctx, command, args...)
+	}
+
+	full := CommandString(sandboxTool, command, args...)
+	return exec.CommandContext(ctx, "sh", "-c", full)
+}
+
 // CommandString builds the shell command string for running command+args with an optional sandbox tool.
 func CommandString(sandboxTool string, command string, args ...string) string {
 	parts := make([]string, 0, len(args)+1)
@@ -32,6 +43,11 @@ func CommandString(sandboxTool string, command string, args ...string) string {
 	return strings.TrimSpace(sandboxTool) + " " + commandLine
 }
 
+// Quote returns value quoted for safe use as a single POSIX shell word.
+func Quote(value string) string {
+	return shellQuote(value)
+}
+
 func shellQuote(value string) string {
 	if value == "" {
 		return "''"
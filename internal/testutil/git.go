@@ -0,0 +1,42 @@
+// Package testutil provides fixtures shared by the tests of several packages.
+package testutil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// NewRepo creates a repository at dir with one empty commit on main and
+// returns a function that runs git in it. Commits made by the test, or by
+// the code under test, are authored by a test identity.
+func NewRepo(t *testing.T, dir string) func(args ...string) string {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	RunGit(t, filepath.Dir(dir), "init", "-q", "-b", "main", dir)
+	RunGit(t, dir, "commit", "-q", "--allow-empty", "-m", "init")
+	return func(args ...string) string {
+		t.Helper()
+		return RunGit(t, dir, args...)
+	}
+}
+
+// RunGit runs git in dir and returns its trimmed output, failing the test if
+// it fails.
+func RunGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -15,27 +15,16 @@ type Window struct {
 	Panes []string
 }
 
-// ParseLayout parses a custom tmux layout string into a slice of Windows.
+// ParseLayout parses custom tmux layout window specs into a slice of Windows.
+// Each spec describes one window, so commands may contain ',' or ';'.
 //
-// Format: window:pane1|pane2;window2:pane3
+// Format: window:pane1|pane2
 // Separators:
-//   - ';' or ',' or newline: Separates windows
 //   - ':': Separates window name from panes
 //   - '|': Separates panes within a window
-func ParseLayout(layout string) ([]Window, error) {
-	if layout == "" {
-		return []Window{}, nil
-	}
-
-	// Normalize separators: replace commas and newlines with semicolons
-	layout = strings.ReplaceAll(layout, ",", ";")
-	layout = strings.ReplaceAll(layout, "\n", ";")
-
-	// Split into window specs
-	windowSpecs := strings.Split(layout, ";")
-
+func ParseLayout(specs []string) ([]Window, error) {
 	windows := make([]Window, 0)
-	for _, spec := range windowSpecs {
+	for _, spec := range specs {
 		spec = strings.TrimSpace(spec)
 		if spec == "" {
 			continue // Skip empty specs
@@ -81,18 +70,74 @@ type SessionConfig struct {
 	WorktreePath string   // Path to the worktree (working directory)
 	SandboxTool  string   // Optional sandbox tool prefix
 	RunCommand   string   // Optional command to run in agent window
-	CustomLayout string   // Optional custom layout string
+	CustomLayout []string // Optional custom layout, one window spec per entry
+	SetupCommand string   // Optional setup command line run inside the session
+	SetupInAgent bool     // Run SetupCommand in the agent window before RunCommand instead of a "setup" window
 	WindowNames  []string // Resulting window names (populated after creation)
 }
 
+// setupWindowName is the window created for SetupCommand when it doesn't run in the agent window.
+const setupWindowName = "setup"
+
+// ValidateLayout parses a custom layout for a session that runs a setup
+// command (hasSetup), in the agent window when setupInAgent is set. Besides
+// ParseLayout's checks, it rejects a "setup" window when the session would
+// add its own for the setup command.
+func ValidateLayout(layout []string, hasSetup bool, setupInAgent bool) ([]Window, error) {
+	windows, err := ParseLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+	if hasSetup && (!setupInAgent || !hasWindow(windows, "agent")) && hasWindow(windows, setupWindowName) {
+		return nil, fmt.Errorf("window name %q is reserved for the pre_session_cmd window", setupWindowName)
+	}
+	return windows, nil
+}
+
+// hasWindow reports whether windows contains a window called name.
+func hasWindow(windows []Window, name string) bool {
+	for _, window := range windows {
+		if window.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// agentCommand returns the command line to type into the agent window.
+// When SetupCommand runs in the agent window, RunCommand only starts if setup succeeds.
+func agentCommand(config *SessionConfig) string {
+	if config.SetupCommand == "" || !config.SetupInAgent {
+		return config.RunCommand
+	}
+	if config.RunCommand == "" {
+		return config.SetupCommand
+	}
+	return config.SetupCommand + " && " + config.RunCommand
+}
+
+// createSetupWindow creates the "setup" window and starts SetupCommand in it.
+func createSetupWindow(config *SessionConfig) error {
+	cmd := sandbox.Command(config.SandboxTool, "tmux", "new-window", "-d", "-t", config.SessionName, "-n", setupWindowName, "-c", config.WorktreePath)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to create setup window: %w", err)
+	}
+	cmd = sandbox.Command(config.SandboxTool, "tmux", "send-keys", "-t", config.SessionName+":"+setupWindowName, config.SetupCommand, "Enter")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send command to setup window: %w", err)
+	}
+	return nil
+}
+
 // CreateDefaultLayout creates a tmux session with the default layout (dev + agent windows).
 //
 // Algorithm:
 // 1. Create new detached session with first window
 // 2. Rename first window to "dev"
 // 3. Create second window named "agent"
-// 4. If RunCommand provided, send it to agent window
-// 5. Select dev window (make it active)
+// 4. If RunCommand (or an agent-window SetupCommand) provided, send it to agent window
+// 5. If SetupCommand provided for a separate window, create "setup" window and start it
+// 6. Select dev window (make it active)
 func CreateDefaultLayout(config *SessionConfig) error {
 	// Step 1: Create new detached session
 	cmd := sandbox.Command(config.SandboxTool, "tmux", "new-session", "-d", "-s", config.SessionName, "-c", config.WorktreePath)
@@ -113,21 +158,30 @@ func CreateDefaultLayout(config *SessionConfig) error {
 	}
 
 	// Step 4: Send command to agent window if provided
-	if config.RunCommand != "" {
-		cmd = sandbox.Command(config.SandboxTool, "tmux", "send-keys", "-t", config.SessionName+":agent", config.RunCommand, "Enter")
+	if command := agentCommand(config); command != "" {
+		cmd = sandbox.Command(config.SandboxTool, "tmux", "send-keys", "-t", config.SessionName+":agent", command, "Enter")
 		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("failed to send command to agent window: %w", err)
 		}
 	}
 
-	// Step 5: Select dev window (make it active)
+	// Step 5: Start setup command in its own window
+	windowNames := []string{"dev", "agent"}
+	if config.SetupCommand != "" && !config.SetupInAgent {
+		if err := createSetupWindow(config); err != nil {
+			return err
+		}
+		windowNames = append(windowNames, setupWindowName)
+	}
+
+	// Step 6: Select dev window (make it active)
 	cmd = sandbox.Command(config.SandboxTool, "tmux", "select-window", "-t", config.SessionName+":dev")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to select dev window: %w", err)
 	}
 
 	// Populate window names
-	config.WindowNames = []string{"dev", "agent"}
+	config.WindowNames = windowNames
 
 	return nil
 }
@@ -154,12 +208,21 @@ func KillSession(sessionName, sandboxTool string) error {
 	return nil
 }
 
+// RenameSession renames an existing tmux session.
+func RenameSession(oldName, newName, sandboxTool string) error {
+	cmd := sandbox.Command(sandboxTool, "tmux", "rename-session", "-t", oldName, newName)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to rename session: %w", err)
+	}
+	return nil
+}
+
 // AttachToSession attaches to an existing tmux session in the current terminal.
 // If windowName is provided, it selects that window before attaching.
 func AttachToSession(sessionName, windowName, sandboxTool string) error {
 	// Validate window name if provided
-	if windowName != "" && windowName != "dev" && windowName != "agent" {
-		return fmt.Errorf("Unknown window: %s (use dev or agent)", windowName)
+	if windowName != "" && windowName != "dev" && windowName != "agent" && windowName != setupWindowName {
+		return fmt.Errorf("Unknown window: %s (use dev, agent or setup)", windowName)
 	}
 
 	// Select window if specified
@@ -181,18 +244,34 @@ func AttachToSession(sessionName, windowName, sandboxTool string) error {
 	return cmd.Run()
 }
 
+// SwitchToSession shows a session in the current terminal: inside tmux it
+// switches the current client to it (attaching would nest tmux), otherwise it
+// attaches.
+func SwitchToSession(sessionName, sandboxTool string) error {
+	if os.Getenv("TMUX") == "" {
+		return AttachToSession(sessionName, "", sandboxTool)
+	}
+	cmd := sandbox.Command(sandboxTool, "tmux", "switch-client", "-t", sessionName)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to switch to session %s: %w", sessionName, err)
+	}
+	return nil
+}
+
 // CreateCustomLayout creates a tmux session with a custom layout defined by the user.
 //
 // Algorithm:
-// 1. Parse layout string
+// 1. Parse and validate layout window specs
 // 2. Create first window with session
 // 3. Create additional windows
 // 4. For each window, create panes and send commands
-// 5. If RunCommand provided and agent window exists, send it
-// 6. Select first window
+// 5. If RunCommand (or an agent-window SetupCommand) provided and agent window exists, send it
+// 6. If SetupCommand provided for a separate window (or there is no agent window), create "setup" window
+// 7. Select first window
 func CreateCustomLayout(config *SessionConfig) error {
-	// Step 1: Parse layout string
-	windows, err := ParseLayout(config.CustomLayout)
+	// Step 1: Parse and validate layout window specs
+	windows, err := ValidateLayout(config.CustomLayout, config.SetupCommand != "", config.SetupInAgent)
 	if err != nil {
 		return fmt.Errorf("invalid tmux layout: %w", err)
 	}
@@ -259,21 +338,24 @@ func CreateCustomLayout(config *SessionConfig) error {
 	}
 
 	// Step 5: If RunCommand provided, send to agent window if it exists
-	if config.RunCommand != "" {
-		// Search for agent window
-		for _, window := range windows {
-			if window.Name == "agent" {
-				target := fmt.Sprintf("%s:agent.0", config.SessionName)
-				cmd := sandbox.Command(config.SandboxTool, "tmux", "send-keys", "-t", target, config.RunCommand, "Enter")
-				if err := cmd.Run(); err != nil {
-					return fmt.Errorf("failed to send command to agent window: %w", err)
-				}
-				break
-			}
+	hasAgent := hasWindow(windows, "agent")
+	if command := agentCommand(config); command != "" && hasAgent {
+		target := fmt.Sprintf("%s:agent.0", config.SessionName)
+		cmd := sandbox.Command(config.SandboxTool, "tmux", "send-keys", "-t", target, command, "Enter")
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to send command to agent window: %w", err)
+		}
+	}
+
+	// Step 6: Start setup command in its own window
+	setupWindow := config.SetupCommand != "" && (!config.SetupInAgent || !hasAgent)
+	if setupWindow {
+		if err := createSetupWindow(config); err != nil {
+			return err
 		}
 	}
 
-	// Step 6: Select first window
+	// Step 7: Select first window
 	target := fmt.Sprintf("%s:%s", config.SessionName, firstWindow.Name)
 	cmd = sandbox.Command(config.SandboxTool, "tmux", "select-window", "-t", target)
 	if err := cmd.Run(); err != nil {
@@ -285,6 +367,9 @@ func CreateCustomLayout(config *SessionConfig) error {
 	for i, window := range windows {
 		config.WindowNames[i] = window.Name
 	}
+	if setupWindow {
+		config.WindowNames = append(config.WindowNames, setupWindowName)
+	}
 
 	return nil
 }
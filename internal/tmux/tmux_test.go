@@ -9,58 +9,40 @@ import (
 func TestParseLayout(t *testing.T) {
 	tests := []struct {
 		name     string
-		input    string
+		input    []string
 		expected []Window
 	}{
 		{
 			name:  "single window with no panes",
-			input: "dev:",
+			input: []string{"dev:"},
 			expected: []Window{
 				{Name: "dev", Panes: []string{""}},
 			},
 		},
 		{
 			name:  "single window with one command",
-			input: "dev:hx",
+			input: []string{"dev:hx"},
 			expected: []Window{
 				{Name: "dev", Panes: []string{"hx"}},
 			},
 		},
 		{
 			name:  "single window with two panes",
-			input: "dev:hx|lazygit",
+			input: []string{"dev:hx|lazygit"},
 			expected: []Window{
 				{Name: "dev", Panes: []string{"hx", "lazygit"}},
 			},
 		},
 		{
 			name:  "single window with three panes",
-			input: "dev:hx|lazygit|",
+			input: []string{"dev:hx|lazygit|"},
 			expected: []Window{
 				{Name: "dev", Panes: []string{"hx", "lazygit", ""}},
 			},
 		},
 		{
-			name:  "multiple windows semicolon separator",
-			input: "dev:hx|lazygit;server:bin/server;agent:",
-			expected: []Window{
-				{Name: "dev", Panes: []string{"hx", "lazygit"}},
-				{Name: "server", Panes: []string{"bin/server"}},
-				{Name: "agent", Panes: []string{""}},
-			},
-		},
-		{
-			name:  "multiple windows comma separator",
-			input: "dev:hx|lazygit,server:bin/server,agent:",
-			expected: []Window{
-				{Name: "dev", Panes: []string{"hx", "lazygit"}},
-				{Name: "server", Panes: []string{"bin/server"}},
-				{Name: "agent", Panes: []string{""}},
-			},
-		},
-		{
-			name:  "multiple windows newline separator",
-			input: "dev:hx|lazygit\nserver:bin/server\nagent:",
+			name:  "multiple windows",
+			input: []string{"dev:hx|lazygit", "server:bin/server", "agent:"},
 			expected: []Window{
 				{Name: "dev", Panes: []string{"hx", "lazygit"}},
 				{Name: "server", Panes: []string{"bin/server"}},
@@ -69,7 +51,7 @@ func TestParseLayout(t *testing.T) {
 		},
 		{
 			name:  "whitespace trimming",
-			input: "  dev : hx | lazygit  ;  server : bin/server  ",
+			input: []string{"  dev : hx | lazygit  ", "  server : bin/server  "},
 			expected: []Window{
 				{Name: "dev", Panes: []string{"hx", "lazygit"}},
 				{Name: "server", Panes: []string{"bin/server"}},
@@ -77,49 +59,42 @@ func TestParseLayout(t *testing.T) {
 		},
 		{
 			name:  "empty panes",
-			input: "dev:||",
+			input: []string{"dev:||"},
 			expected: []Window{
 				{Name: "dev", Panes: []string{"", "", ""}},
 			},
 		},
 		{
 			name:  "complex commands with shell metacharacters",
-			input: "server:cd api && bin/server|cd ui && yarn start;logs:tail -f log/development.log",
+			input: []string{"server:cd api && bin/server|cd ui && yarn start", "logs:tail -f log/development.log"},
 			expected: []Window{
 				{Name: "server", Panes: []string{"cd api && bin/server", "cd ui && yarn start"}},
 				{Name: "logs", Panes: []string{"tail -f log/development.log"}},
 			},
 		},
 		{
-			name:  "empty windows ignored",
-			input: "dev:hx;;server:bin/server",
+			name:  "separators kept inside a window spec",
+			input: []string{"server:bin/server --hosts a,b; echo done"},
 			expected: []Window{
-				{Name: "dev", Panes: []string{"hx"}},
-				{Name: "server", Panes: []string{"bin/server"}},
+				{Name: "server", Panes: []string{"bin/server --hosts a,b; echo done"}},
 			},
 		},
 		{
-			name:  "mixed separators",
-			input: "dev:hx|lazygit;server:bin/server\nagent:",
+			name:  "empty windows ignored",
+			input: []string{"dev:hx", "", "  ", "server:bin/server"},
 			expected: []Window{
-				{Name: "dev", Panes: []string{"hx", "lazygit"}},
+				{Name: "dev", Panes: []string{"hx"}},
 				{Name: "server", Panes: []string{"bin/server"}},
-				{Name: "agent", Panes: []string{""}},
 			},
 		},
 		{
 			name:     "empty input",
-			input:    "",
-			expected: []Window{},
-		},
-		{
-			name:     "only separators",
-			input:    ";;;",
+			input:    nil,
 			expected: []Window{},
 		},
 		{
 			name:  "window with colon in command",
-			input: "dev:echo 'time: 10:30'",
+			input: []string{"dev:echo 'time: 10:30'"},
 			expected: []Window{
 				{Name: "dev", Panes: []string{"echo 'time: 10:30'"}},
 			},
@@ -143,15 +118,15 @@ func TestParseLayout(t *testing.T) {
 func TestParseLayoutErrors(t *testing.T) {
 	tests := []struct {
 		name  string
-		input string
+		input []string
 	}{
 		{
 			name:  "window without name",
-			input: ":hx|lazygit",
+			input: []string{":hx|lazygit"},
 		},
 		{
 			name:  "missing colon separator",
-			input: "dev hx lazygit",
+			input: []string{"dev hx lazygit"},
 		},
 	}
 
@@ -159,7 +134,54 @@ func TestParseLayoutErrors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := ParseLayout(tt.input)
 			if err == nil {
-				t.Errorf("ParseLayout() should return error for invalid input: %s", tt.input)
+				t.Errorf("ParseLayout() should return error for invalid input: %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	tests := []struct {
+		name         string
+		layout       []string
+		hasSetup     bool
+		setupInAgent bool
+		wantErr      bool
+	}{
+		{name: "setup window without setup command", layout: []string{"dev:hx", "setup:bin/setup"}},
+		{name: "setup window with window mode", layout: []string{"dev:hx", "setup:bin/setup"}, hasSetup: true, wantErr: true},
+		{name: "setup window with agent mode", layout: []string{"setup:bin/setup", "agent:"}, hasSetup: true, setupInAgent: true},
+		{name: "setup window with agent mode and no agent window", layout: []string{"dev:hx", "setup:bin/setup"}, hasSetup: true, setupInAgent: true, wantErr: true},
+		{name: "no setup window", layout: []string{"dev:hx", "agent:"}, hasSetup: true},
+		{name: "invalid spec", layout: []string{"dev hx"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateLayout(tt.layout, tt.hasSetup, tt.setupInAgent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAgentCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SessionConfig
+		expected string
+	}{
+		{name: "run only", config: SessionConfig{RunCommand: "claude"}, expected: "claude"},
+		{name: "setup in window", config: SessionConfig{RunCommand: "claude", SetupCommand: "npm install"}, expected: "claude"},
+		{name: "setup in agent", config: SessionConfig{RunCommand: "claude", SetupCommand: "npm install", SetupInAgent: true}, expected: "npm install && claude"},
+		{name: "setup in agent without run", config: SessionConfig{SetupCommand: "npm install", SetupInAgent: true}, expected: "npm install"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := agentCommand(&tt.config); result != tt.expected {
+				t.Errorf("agentCommand() = %q, want %q", result, tt.expected)
 			}
 		})
 	}
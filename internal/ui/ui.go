@@ -158,3 +158,11 @@ func RedText(text string) string {
 	}
 	return Red + text + Reset
 }
+
+// YellowText returns the text wrapped with yellow color
+func YellowText(text string) string {
+	if Yellow == "" {
+		return text
+	}
+	return Yellow + text + Reset
+}
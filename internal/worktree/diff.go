@@ -0,0 +1,97 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Diff output formats.
+const (
+	DiffPatch    = ""          // Full patch (default)
+	DiffStat     = "stat"      // git diff --stat
+	DiffNameOnly = "name-only" // git diff --name-only
+)
+
+// Diff shows every change in a worktree relative to baseCommit: committed,
+// staged, unstaged and untracked (but not ignored) files.
+//
+// Untracked files are included by staging the whole worktree into a temporary
+// copy of its index, so the real index is never modified. When tool is set,
+// the changes are opened with git difftool instead of printed.
+// Uses: git add -A; git diff --cached <base> (with GIT_INDEX_FILE set)
+func Diff(worktreePath, baseCommit, format, tool string) error {
+	indexPath, cleanup, err := snapshotIndex(worktreePath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	env := append(os.Environ(), "GIT_INDEX_FILE="+indexPath)
+
+	add := exec.Command("git", "-C", worktreePath, "add", "-A")
+	add.Env = env
+	add.Stdout = io.Discard
+	add.Stderr = os.Stderr
+	if err := add.Run(); err != nil {
+		return fmt.Errorf("failed to stage worktree snapshot: %w", err)
+	}
+
+	args := []string{"-C", worktreePath}
+	if tool != "" {
+		args = append(args, "difftool", "--cached", "--no-prompt", "--tool", tool)
+	} else {
+		args = append(args, "diff", "--cached")
+		if format != DiffPatch {
+			args = append(args, "--"+format)
+		}
+	}
+	args = append(args, baseCommit)
+
+	cmd := exec.Command("git", args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w", args[2], err)
+	}
+	return nil
+}
+
+// snapshotIndex copies a worktree's index to a temporary file and returns its
+// path with a cleanup function.
+// Uses: git rev-parse --git-path index
+func snapshotIndex(worktreePath string) (string, func(), error) {
+	output, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", "index").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to locate index for %s: %w", worktreePath, err)
+	}
+	source := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(worktreePath, source)
+	}
+
+	snapshot, err := os.CreateTemp("", "mxt-diff-index-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	cleanup := func() { _ = os.Remove(snapshot.Name()) }
+
+	data, err := os.ReadFile(source)
+	if err != nil && !os.IsNotExist(err) {
+		snapshot.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	_, writeErr := snapshot.Write(data)
+	closeErr := snapshot.Close()
+	if writeErr != nil || closeErr != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temporary index: %w", errors.Join(writeErr, closeErr))
+	}
+	return snapshot.Name(), cleanup, nil
+}
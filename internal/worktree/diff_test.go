@@ -0,0 +1,59 @@
+package worktree
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+// TestDiffIncludesUncommittedAndUntracked checks Diff covers every change without touching the index
+func TestDiffIncludesUncommittedAndUntracked(t *testing.T) {
+	repo := t.TempDir()
+	repoGit := testutil.NewRepo(t, repo)
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	writeFile(".gitignore", "ignored.log\n")
+	writeFile("tracked.txt", "base\n")
+	repoGit("add", "-A")
+	repoGit("commit", "-q", "-m", "base")
+	base := repoGit("rev-parse", "HEAD")
+
+	writeFile("committed.txt", "x\n")
+	repoGit("add", "committed.txt")
+	repoGit("commit", "-q", "-m", "committed")
+	writeFile("staged.txt", "x\n")
+	repoGit("add", "staged.txt")
+	writeFile("tracked.txt", "changed\n")
+	writeFile("untracked.txt", "x\n")
+	writeFile("ignored.log", "x\n")
+	statusBefore := repoGit("status", "--porcelain")
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := Diff(repo, base, DiffNameOnly, "")
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	expected := "committed.txt\nstaged.txt\ntracked.txt\nuntracked.txt"
+	if got := strings.TrimSpace(string(out)); got != expected {
+		t.Errorf("Diff() output = %q, want %q", got, expected)
+	}
+	if statusAfter := repoGit("status", "--porcelain"); statusAfter != statusBefore {
+		t.Errorf("git status changed by Diff():\nbefore:\n%s\nafter:\n%s", statusBefore, statusAfter)
+	}
+}
@@ -0,0 +1,126 @@
+package worktree
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gkarolyi/mxt/internal/git"
+)
+
+// Strategies for Integrate.
+const (
+	StrategyMerge  = "merge"  // git merge --no-ff (default)
+	StrategySquash = "squash" // git merge --squash, then a single commit
+	StrategyRebase = "rebase" // Rebase the branch onto the target, then fast-forward
+)
+
+// Strategies lists every supported integration strategy.
+var Strategies = []string{StrategyMerge, StrategySquash, StrategyRebase}
+
+// IsStrategy reports whether name is a supported integration strategy.
+func IsStrategy(name string) bool {
+	for _, strategy := range Strategies {
+		if strategy == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Integrate integrates branch into the checkout at dir, which has the target
+// branch (or a detached HEAD at it) checked out. branchPath is the branch's own
+// worktree, where it is rebased for StrategyRebase.
+//
+// On conflicts the merge or rebase is aborted and an error is returned.
+func Integrate(dir, branch, branchPath, strategy string) error {
+	switch strategy {
+	case StrategyMerge:
+		if err := runGitIn(dir, "merge", "--no-ff", "--no-edit", branch); err != nil {
+			abortGit(dir, "merge", "--abort")
+			return fmt.Errorf("git merge failed: %w", err)
+		}
+	case StrategySquash:
+		message, err := squashMessage(dir, branch)
+		if err != nil {
+			return err
+		}
+		if err := runGitIn(dir, "merge", "--squash", branch); err != nil {
+			abortGit(dir, "reset", "--merge")
+			return fmt.Errorf("git merge --squash failed: %w", err)
+		}
+		if err := runGitIn(dir, "commit", "-m", message); err != nil {
+			abortGit(dir, "reset", "--merge")
+			return fmt.Errorf("git commit failed: %w", err)
+		}
+	case StrategyRebase:
+		target, err := git.ResolveCommit(dir, "HEAD")
+		if err != nil {
+			return err
+		}
+		if err := runGitIn(branchPath, "rebase", target); err != nil {
+			abortGit(branchPath, "rebase", "--abort")
+			return fmt.Errorf("git rebase failed: %w", err)
+		}
+		if err := runGitIn(dir, "merge", "--ff-only", branch); err != nil {
+			return fmt.Errorf("git merge --ff-only failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q", strategy)
+	}
+	return nil
+}
+
+// squashMessage builds the commit message for a squash merge: a subject naming
+// the branch, and the subjects of the squashed commits as the body.
+// Uses: git log --reverse --format=* %s HEAD..<branch>
+func squashMessage(dir, branch string) (string, error) {
+	cmd := exec.Command("git", "-C", dir, "log", "--reverse", "--format=* %s", "HEAD.."+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git log failed: %w", err)
+	}
+	return fmt.Sprintf("Squash merge branch '%s'\n\n%s", branch, strings.TrimSpace(string(output))), nil
+}
+
+// AddTemporary creates a worktree with a detached HEAD at rev in a new
+// temporary directory, for integrating without touching the main checkout.
+// Remove it with Remove.
+// Uses: git worktree add --detach <tmp> <rev>
+func AddTemporary(rev string) (string, error) {
+	tempDir, err := os.MkdirTemp("", "mxt-finish-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cmd := exec.Command("git", "worktree", "add", "--detach", tempDir, rev)
+	cmd.Stdout = io.Discard
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.RemoveAll(tempDir)
+		return "", fmt.Errorf("git worktree add failed: %w", err)
+	}
+	return tempDir, nil
+}
+
+// runGitIn runs a git command in dir, passing its output through.
+func runGitIn(dir string, args ...string) error {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// abortGit runs a cleanup command (e.g. merge --abort) and ignores its result.
+func abortGit(dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	_ = cmd.Run()
+}
+
+// FastForward advances the branch checked out in dir to rev. Local changes
+// that don't conflict with the update are kept.
+// Uses: git merge --ff-only <rev>
+func FastForward(dir, rev string) error {
+	return runGitIn(dir, "merge", "--ff-only", rev)
+}
@@ -0,0 +1,96 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+// TestIntegrate integrates a feature branch into main with each strategy
+func TestIntegrate(t *testing.T) {
+	tests := []struct {
+		strategy string
+		subject  string // Expected subject of main's HEAD commit
+		parents  int    // Expected parent count of main's HEAD commit
+		commits  string // Expected commit count on main
+	}{
+		{strategy: StrategyMerge, subject: "Merge branch 'feature'", parents: 2, commits: "5"},
+		{strategy: StrategySquash, subject: "Squash merge branch 'feature'", parents: 1, commits: "3"},
+		{strategy: StrategyRebase, subject: "feature 2", parents: 1, commits: "4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			root := t.TempDir()
+			repo := filepath.Join(root, "repo")
+			branchPath := filepath.Join(root, "feature")
+			commitFile := func(dir, name string) {
+				t.Helper()
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+				testutil.RunGit(t, dir, "add", name)
+				testutil.RunGit(t, dir, "commit", "-q", "-m", name)
+			}
+
+			testutil.NewRepo(t, repo)
+			testutil.RunGit(t, repo, "worktree", "add", "-q", "-b", "feature", branchPath, "main")
+			commitFile(branchPath, "feature 1")
+			commitFile(branchPath, "feature 2")
+			commitFile(repo, "main moves")
+
+			if err := Integrate(repo, "feature", branchPath, tt.strategy); err != nil {
+				t.Fatalf("Integrate() error = %v", err)
+			}
+
+			if subject := testutil.RunGit(t, repo, "log", "-1", "--format=%s", "main"); subject != tt.subject {
+				t.Errorf("HEAD subject = %q, want %q", subject, tt.subject)
+			}
+			if parents := strings.Fields(testutil.RunGit(t, repo, "log", "-1", "--format=%P", "main")); len(parents) != tt.parents {
+				t.Errorf("HEAD parents = %d, want %d", len(parents), tt.parents)
+			}
+			if count := testutil.RunGit(t, repo, "rev-list", "--count", "main"); count != tt.commits {
+				t.Errorf("commits on main = %s, want %s", count, tt.commits)
+			}
+			for _, file := range []string{"feature 1", "feature 2", "main moves"} {
+				if _, err := os.Stat(filepath.Join(repo, file)); err != nil {
+					t.Errorf("%s missing from main checkout: %v", file, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIntegrateAbortsOnConflict(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	branchPath := filepath.Join(root, "feature")
+	writeAndCommit := func(dir, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "shared.txt"), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write shared.txt: %v", err)
+		}
+		testutil.RunGit(t, dir, "add", "shared.txt")
+		testutil.RunGit(t, dir, "commit", "-q", "-m", content)
+	}
+
+	testutil.NewRepo(t, repo)
+	writeAndCommit(repo, "base")
+	testutil.RunGit(t, repo, "worktree", "add", "-q", "-b", "feature", branchPath, "main")
+	writeAndCommit(branchPath, "feature")
+	writeAndCommit(repo, "main")
+	head := testutil.RunGit(t, repo, "rev-parse", "HEAD")
+
+	if err := Integrate(repo, "feature", branchPath, StrategyMerge); err == nil {
+		t.Fatal("Integrate() expected conflict error")
+	}
+	if after := testutil.RunGit(t, repo, "rev-parse", "HEAD"); after != head {
+		t.Errorf("HEAD moved to %s after failed merge, want %s", after, head)
+	}
+	if status := testutil.RunGit(t, repo, "status", "--porcelain"); status != "" {
+		t.Errorf("main checkout left dirty after aborted merge:\n%s", status)
+	}
+}
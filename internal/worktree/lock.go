@@ -0,0 +1,46 @@
+package worktree
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/gkarolyi/mxt/internal/git"
+)
+
+// Lock locks a worktree so git worktree prune, move and remove leave it alone,
+// and mxt refuses to delete it. reason may be empty.
+// Uses: git worktree lock [--reason <reason>] <path>
+func Lock(worktreePath, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	args = append(args, worktreePath)
+	cmd := exec.Command("git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree lock failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Unlock removes a worktree's lock.
+// Uses: git worktree unlock <path>
+func Unlock(worktreePath string) error {
+	cmd := exec.Command("git", "worktree", "unlock", worktreePath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree unlock failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// LockStatus reports whether the worktree at worktreePath is locked, and the
+// lock reason (empty if none was given). It asks git worktree list, so a lock
+// is reported even when the worktree's directory is unavailable.
+func LockStatus(worktreePath string) (bool, string, error) {
+	wt, found, err := git.FindWorktree(worktreePath)
+	if err != nil || !found {
+		return false, "", err
+	}
+	return wt.Locked, wt.LockReason, nil
+}
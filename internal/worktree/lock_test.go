@@ -0,0 +1,68 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+// TestLockBlocksRemove locks a worktree, checks that Remove refuses to delete
+// it, then unlocks and removes it
+func TestLockBlocksRemove(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	linked := filepath.Join(root, "release")
+	repoGit := testutil.NewRepo(t, repo)
+	repoGit("worktree", "add", "-q", "-b", "release", linked)
+	t.Chdir(repo)
+
+	if locked, _, err := LockStatus(linked); err != nil || locked {
+		t.Fatalf("LockStatus() = %v, %v before Lock; want unlocked", locked, err)
+	}
+	if err := Lock(linked, "release freeze"); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	locked, reason, err := LockStatus(linked)
+	if err != nil || !locked || reason != "release freeze" {
+		t.Fatalf("LockStatus() = %v, %q, %v; want true, %q", locked, reason, err, "release freeze")
+	}
+
+	// A worktree on an unmounted drive is still locked
+	unmounted := linked + ".unmounted"
+	if err := os.Rename(linked, unmounted); err != nil {
+		t.Fatal(err)
+	}
+	if locked, _, err := LockStatus(linked); err != nil || !locked {
+		t.Errorf("LockStatus(missing directory) = %v, %v; want locked", locked, err)
+	}
+	if err := Remove(linked); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("Remove(missing directory) error = %v, want locked error", err)
+	}
+	if err := os.Rename(unmounted, linked); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Remove(linked)
+	if err == nil || !strings.Contains(err.Error(), "locked (release freeze)") {
+		t.Fatalf("Remove(locked) error = %v, want locked error", err)
+	}
+	if _, err := os.Stat(linked); err != nil {
+		t.Fatalf("locked worktree was removed: %v", err)
+	}
+
+	if err := Unlock(linked); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if locked, _, err := LockStatus(linked); err != nil || locked {
+		t.Fatalf("LockStatus() = %v, %v after Unlock; want unlocked", locked, err)
+	}
+	if err := Remove(linked); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(linked); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists after Remove: %v", err)
+	}
+}
@@ -0,0 +1,354 @@
+package worktree
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gkarolyi/mxt/internal/git"
+)
+
+var listIgnoredFiles = git.ListIgnoredFiles
+
+// CopyPlan describes the files selected by a copy_files pattern list.
+type CopyPlan struct {
+	Files   []string // Matched files, relative to the source directory (slash-separated)
+	Missing []string // Glob patterns that matched nothing
+	Invalid []string // Patterns that could not be parsed
+}
+
+// ResolveCopyFiles expands the copy_files patterns relative to sourceDir.
+//
+// Pattern syntax:
+//   - Standard shell globs (*, ?, [...]) match within a single path segment
+//   - '**' matches zero or more directories (e.g. services/**/.env.local)
+//   - A leading '!' excludes matching files from the result (e.g. !**/node_modules/**)
+//   - A matched directory contributes every file beneath it
+//
+// Exclusions apply to the final set regardless of their position in the list,
+// and excluding a directory excludes everything beneath it.
+func ResolveCopyFiles(sourceDir string, copyFiles []string) CopyPlan {
+	var plan CopyPlan
+	includes, excludes, invalid := splitPatterns(copyFiles)
+	plan.Invalid = invalid
+
+	seen := make(map[string]struct{})
+	for _, pattern := range includes {
+		matches, err := expandPattern(sourceDir, cleanPattern(pattern))
+		if err != nil {
+			plan.Invalid = append(plan.Invalid, pattern)
+			continue
+		}
+		if len(matches) == 0 {
+			if strings.ContainsAny(pattern, "*?[") {
+				plan.Missing = append(plan.Missing, pattern)
+			}
+			continue
+		}
+		for _, relPath := range matches {
+			if isExcluded(relPath, excludes) {
+				continue
+			}
+			if _, ok := seen[relPath]; ok {
+				continue
+			}
+			seen[relPath] = struct{}{}
+			plan.Files = append(plan.Files, relPath)
+		}
+	}
+
+	sort.Strings(plan.Files)
+	return plan
+}
+
+// ResolveIgnoredFiles selects git-ignored files in sourceDir using the
+// copy_ignored patterns. Patterns use the same syntax as copy_files, but are
+// matched against the list from git.ListIgnoredFiles instead of the filesystem,
+// so only files git ignores can be selected.
+func ResolveIgnoredFiles(sourceDir string, copyIgnored []string) (CopyPlan, error) {
+	ignored, err := listIgnoredFiles(sourceDir)
+	if err != nil {
+		return CopyPlan{}, fmt.Errorf("failed to list ignored files: %w", err)
+	}
+	return FilterPaths(ignored, copyIgnored), nil
+}
+
+// FilterPaths selects the slash-separated paths matching a pattern list.
+// Include patterns are matched against each path; '!' patterns remove
+// matches, including everything beneath an excluded directory.
+func FilterPaths(paths, patterns []string) CopyPlan {
+	var plan CopyPlan
+	includes, excludes, invalid := splitPatterns(patterns)
+	plan.Invalid = invalid
+
+	seen := make(map[string]struct{})
+	for _, pattern := range includes {
+		cleaned := cleanPattern(pattern)
+		if _, err := matchPath(cleaned, ""); err != nil {
+			plan.Invalid = append(plan.Invalid, pattern)
+			continue
+		}
+		matched := false
+		for _, relPath := range paths {
+			if !matchesOrWithin(cleaned, relPath) {
+				continue
+			}
+			matched = true
+			if isExcluded(relPath, excludes) {
+				continue
+			}
+			if _, ok := seen[relPath]; ok {
+				continue
+			}
+			seen[relPath] = struct{}{}
+			plan.Files = append(plan.Files, relPath)
+		}
+		if !matched {
+			plan.Missing = append(plan.Missing, pattern)
+		}
+	}
+
+	sort.Strings(plan.Files)
+	return plan
+}
+
+// BuildCopyPlan combines the copy_files and copy_ignored selections for sourceDir
+// into a single de-duplicated plan.
+func BuildCopyPlan(sourceDir string, copyFiles, copyIgnored []string) (CopyPlan, error) {
+	plan := ResolveCopyFiles(sourceDir, copyFiles)
+	if len(copyIgnored) == 0 {
+		return plan, nil
+	}
+
+	ignoredPlan, err := ResolveIgnoredFiles(sourceDir, copyIgnored)
+	if err != nil {
+		return plan, err
+	}
+
+	seen := make(map[string]struct{}, len(plan.Files))
+	for _, relPath := range plan.Files {
+		seen[relPath] = struct{}{}
+	}
+	for _, relPath := range ignoredPlan.Files {
+		if _, ok := seen[relPath]; ok {
+			continue
+		}
+		seen[relPath] = struct{}{}
+		plan.Files = append(plan.Files, relPath)
+	}
+	plan.Missing = append(plan.Missing, ignoredPlan.Missing...)
+	plan.Invalid = append(plan.Invalid, ignoredPlan.Invalid...)
+	sort.Strings(plan.Files)
+	return plan, nil
+}
+
+// splitPatterns separates a pattern list into include patterns
+// and cleaned '!' exclusion patterns. Malformed exclusions are returned as invalid.
+func splitPatterns(patterns []string) (includes, excludes, invalid []string) {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if !strings.HasPrefix(pattern, "!") {
+			includes = append(includes, pattern)
+			continue
+		}
+		exclude := cleanPattern(strings.TrimPrefix(pattern, "!"))
+		if exclude == "" {
+			continue
+		}
+		if _, err := matchPath(exclude, ""); err != nil {
+			invalid = append(invalid, pattern)
+			continue
+		}
+		excludes = append(excludes, exclude)
+	}
+	return includes, excludes, invalid
+}
+
+// cleanPattern normalizes a pattern to slash-separated form without a leading "./".
+func cleanPattern(pattern string) string {
+	pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+	for strings.HasPrefix(pattern, "./") {
+		pattern = strings.TrimPrefix(pattern, "./")
+	}
+	return pattern
+}
+
+// expandPattern returns the files matched by a single include pattern,
+// relative to sourceDir. Matched directories are expanded recursively.
+func expandPattern(sourceDir, pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(filepath.Join(sourceDir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		var files []string
+		for _, match := range matches {
+			expanded, err := expandMatch(sourceDir, match)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, expanded...)
+		}
+		return files, nil
+	}
+
+	if _, err := matchPath(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// Walk only the literal prefix of the pattern (e.g. "services" for services/**/.env)
+	walkRoot := sourceDir
+	segments := strings.Split(pattern, "/")
+	for _, segment := range segments[:len(segments)-1] {
+		if segment == "**" || strings.ContainsAny(segment, "*?[") {
+			break
+		}
+		walkRoot = filepath.Join(walkRoot, segment)
+	}
+
+	var files []string
+	err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(sourceDir, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if ok, _ := matchPath(pattern, relPath); ok {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// expandMatch returns the relative path of a matched file, or of every file
+// beneath a matched directory.
+func expandMatch(sourceDir, match string) ([]string, error) {
+	info, err := os.Stat(match)
+	if err != nil {
+		return nil, nil
+	}
+	if !info.IsDir() {
+		relPath, err := filepath.Rel(sourceDir, match)
+		if err != nil {
+			return nil, err
+		}
+		return []string{filepath.ToSlash(relPath)}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(match, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(sourceDir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	return files, err
+}
+
+// matchesOrWithin reports whether relPath matches pattern, or lies beneath a
+// directory that matches it.
+func matchesOrWithin(pattern, relPath string) bool {
+	segments := strings.Split(relPath, "/")
+	for i := len(segments); i > 0; i-- {
+		if ok, _ := matchPath(pattern, strings.Join(segments[:i], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcluded reports whether relPath, or any directory containing it, matches an exclusion.
+func isExcluded(relPath string, excludes []string) bool {
+	if len(excludes) == 0 {
+		return false
+	}
+	for _, exclude := range excludes {
+		if matchesOrWithin(exclude, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether a slash-separated name matches pattern.
+// It behaves like path.Match, except that a "**" segment matches zero or more
+// path segments.
+func matchPath(pattern, name string) (bool, error) {
+	var nameSegments []string
+	if name != "" {
+		nameSegments = strings.Split(name, "/")
+	}
+	patternSegments := strings.Split(pattern, "/")
+
+	// Validate every segment up front so malformed patterns always error
+	for _, segment := range patternSegments {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return false, err
+		}
+	}
+
+	return matchSegments(patternSegments, nameSegments), nil
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,177 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestMatchPath tests glob matching with ** support
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{name: "literal match", pattern: ".env", path: ".env", expected: true},
+		{name: "single segment glob", pattern: ".env*", path: ".env.local", expected: true},
+		{name: "glob does not cross segments", pattern: "*.env", path: "api/.env", expected: false},
+		{name: "double star at root", pattern: "**/.env", path: ".env", expected: true},
+		{name: "double star nested", pattern: "**/.env", path: "services/api/.env", expected: true},
+		{name: "double star in middle", pattern: "services/**/.env.local", path: "services/a/b/.env.local", expected: true},
+		{name: "double star zero dirs", pattern: "services/**/.env.local", path: "services/.env.local", expected: true},
+		{name: "double star wrong prefix", pattern: "services/**/.env.local", path: "apps/a/.env.local", expected: false},
+		{name: "trailing double star", pattern: "node_modules/**", path: "node_modules/pkg/index.js", expected: true},
+		{name: "extra segments rejected", pattern: "config/*.json", path: "config/nested/a.json", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := matchPath(tt.pattern, tt.path)
+			if err != nil {
+				t.Fatalf("matchPath(%q, %q) error = %v", tt.pattern, tt.path, err)
+			}
+			if result != tt.expected {
+				t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchPathInvalidPattern(t *testing.T) {
+	if _, err := matchPath("config/[", "config/a"); err == nil {
+		t.Fatal("matchPath() expected error for malformed pattern")
+	}
+}
+
+// TestResolveCopyFiles tests pattern expansion, exclusions, and missing-pattern reporting
+func TestResolveCopyFiles(t *testing.T) {
+	sourceDir := t.TempDir()
+	files := []string{
+		".env",
+		".env.local",
+		"CLAUDE.md",
+		"services/api/.env.local",
+		"services/web/deep/.env.local",
+		"services/web/node_modules/pkg/.env.local",
+		".claude/settings.json",
+		".claude/cache/state.json",
+		".git/config",
+		"notes,draft.md",
+	}
+	for _, file := range files {
+		path := filepath.Join(sourceDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		copyFiles       []string
+		expectedFiles   []string
+		expectedMissing []string
+	}{
+		{
+			name:          "literal and glob",
+			copyFiles:     []string{".env*", "CLAUDE.md"},
+			expectedFiles: []string{".env", ".env.local", "CLAUDE.md"},
+		},
+		{
+			name:          "recursive glob with exclusion",
+			copyFiles:     []string{"services/**/.env.local", "!**/node_modules/**"},
+			expectedFiles: []string{"services/api/.env.local", "services/web/deep/.env.local"},
+		},
+		{
+			name:          "directory with excluded subdirectory",
+			copyFiles:     []string{"!.claude/cache", ".claude"},
+			expectedFiles: []string{".claude/settings.json"},
+		},
+		{
+			name:          "recursive glob skips .git",
+			copyFiles:     []string{"**/config"},
+			expectedFiles: nil,
+			expectedMissing: []string{
+				"**/config",
+			},
+		},
+		{
+			name:          "literal missing file is silent",
+			copyFiles:     []string{"missing.txt"},
+			expectedFiles: nil,
+		},
+		{
+			name:          "comma in file name",
+			copyFiles:     []string{"notes,draft.md"},
+			expectedFiles: []string{"notes,draft.md"},
+		},
+		{
+			name:          "duplicates collapsed",
+			copyFiles:     []string{".env", ".env*"},
+			expectedFiles: []string{".env", ".env.local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := ResolveCopyFiles(sourceDir, tt.copyFiles)
+			if !reflect.DeepEqual(plan.Files, tt.expectedFiles) {
+				t.Errorf("ResolveCopyFiles(%q).Files = %v, want %v", tt.copyFiles, plan.Files, tt.expectedFiles)
+			}
+			if !reflect.DeepEqual(plan.Missing, tt.expectedMissing) {
+				t.Errorf("ResolveCopyFiles(%q).Missing = %v, want %v", tt.copyFiles, plan.Missing, tt.expectedMissing)
+			}
+		})
+	}
+}
+
+// TestFilterPaths tests selecting from a list of git-ignored paths
+func TestFilterPaths(t *testing.T) {
+	ignored := []string{
+		".env",
+		"api/.env.local",
+		"node_modules/pkg/.env",
+		".claude/settings.local.json",
+		"build/out.bin",
+	}
+
+	plan := FilterPaths(ignored, []string{"**/.env*", ".claude", "!node_modules", "tmp/**"})
+
+	expectedFiles := []string{".claude/settings.local.json", ".env", "api/.env.local"}
+	if !reflect.DeepEqual(plan.Files, expectedFiles) {
+		t.Errorf("FilterPaths().Files = %v, want %v", plan.Files, expectedFiles)
+	}
+	expectedMissing := []string{"tmp/**"}
+	if !reflect.DeepEqual(plan.Missing, expectedMissing) {
+		t.Errorf("FilterPaths().Missing = %v, want %v", plan.Missing, expectedMissing)
+	}
+}
+
+func TestBuildCopyPlanMergesIgnoredFiles(t *testing.T) {
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, ".env"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write .env: %v", err)
+	}
+
+	original := listIgnoredFiles
+	listIgnoredFiles = func(repoRoot string) ([]string, error) {
+		return []string{".env", "config/local.yml"}, nil
+	}
+	t.Cleanup(func() {
+		listIgnoredFiles = original
+	})
+
+	plan, err := BuildCopyPlan(sourceDir, []string{".env"}, []string{"config/*.yml"})
+	if err != nil {
+		t.Fatalf("BuildCopyPlan() error = %v", err)
+	}
+	expected := []string{".env", "config/local.yml"}
+	if !reflect.DeepEqual(plan.Files, expected) {
+		t.Errorf("BuildCopyPlan().Files = %v, want %v", plan.Files, expected)
+	}
+}
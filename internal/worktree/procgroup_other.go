@@ -0,0 +1,9 @@
+//go:build !unix
+
+package worktree
+
+import "os/exec"
+
+// killProcessGroupOnCancel leaves cmd as is: without process groups only the
+// shell is killed when its context is cancelled.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,84 @@
+//go:build unix
+
+package worktree
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestRunPreSessionCommandTimeoutKillsChildren checks that the timeout stops
+// the commands the shell started, not just the shell.
+func TestRunPreSessionCommandTimeoutKillsChildren(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "sleep.pid")
+
+	start := time.Now()
+	err := RunPreSessionCommand(dir, "sleep 60 & echo $! > sleep.pid; wait", "", 500*time.Millisecond)
+	var preErr PreSessionError
+	if !errors.As(err, &preErr) || !preErr.TimedOut {
+		t.Fatalf("RunPreSessionCommand() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("RunPreSessionCommand() took %s, want it to return at the timeout", elapsed)
+	}
+	waitForExit(t, pidFile)
+}
+
+// TestSetupCommandLineTimeoutKillsGrandchildren runs a window/agent mode
+// setup command whose shell starts another shell, and checks that the
+// timeout stops the innermost process too.
+func TestSetupCommandLineTimeoutKillsGrandchildren(t *testing.T) {
+	dir := t.TempDir()
+	line := SetupCommandLine(`sh -c 'sleep 60 & echo $! > sleep.pid; wait'`, filepath.Join(dir, "status"), time.Second)
+	cmd := exec.Command("sh", "-c", line)
+	cmd.Dir = dir
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); !errors.As(err, &exitErr) || exitErr.ExitCode() != timeoutExitCode {
+		t.Fatalf("setup command line error = %v, want exit %d", err, timeoutExitCode)
+	}
+	waitForExit(t, filepath.Join(dir, "sleep.pid"))
+}
+
+// waitForExit fails the test unless the process whose pid is in pidFile
+// exits within a few seconds.
+func waitForExit(t *testing.T, pidFile string) {
+	t.Helper()
+	content, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("failed to read the background pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		t.Fatalf("invalid pid %q: %v", content, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for processRunning(pid) {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("background sleep %d still running after the timeout", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// processRunning reports whether pid is alive and not a zombie waiting to be
+// reaped (as orphans may be in a container without an init process).
+func processRunning(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
@@ -0,0 +1,43 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profileFile records the config profile a worktree was created with, so
+// mxt sessions open and relaunch can apply it again.
+const profileFile = "mxt-profile"
+
+// profilePath returns the profile record for a worktree.
+func profilePath(worktreePath string) (string, error) {
+	dir, err := gitDir(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileFile), nil
+}
+
+// RecordProfile records the profile a worktree uses.
+func RecordProfile(worktreePath, profile string) error {
+	path, err := profilePath(worktreePath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(profile+"\n"), 0o644)
+}
+
+// ReadProfile returns the profile recorded for a worktree, if any.
+func ReadProfile(worktreePath string) (string, bool) {
+	path, err := profilePath(worktreePath)
+	if err != nil {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	profile := strings.TrimSpace(string(content))
+	return profile, profile != ""
+}
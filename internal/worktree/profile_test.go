@@ -0,0 +1,34 @@
+package worktree
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+// TestRecordProfile records a profile for a linked worktree and checks it is
+// kept out of the main checkout's record
+func TestRecordProfile(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	linked := filepath.Join(root, "feature")
+	repoGit := testutil.NewRepo(t, repo)
+	repoGit("worktree", "add", "-q", "-b", "feature", linked)
+
+	if profile, ok := ReadProfile(linked); ok {
+		t.Fatalf("ReadProfile() = %q before RecordProfile", profile)
+	}
+	if err := RecordProfile(linked, "backend"); err != nil {
+		t.Fatalf("RecordProfile() error = %v", err)
+	}
+	if profile, ok := ReadProfile(linked); !ok || profile != "backend" {
+		t.Errorf("ReadProfile() = %q, %v; want %q, true", profile, ok, "backend")
+	}
+	if profile, ok := ReadProfile(repo); ok {
+		t.Errorf("ReadProfile(main checkout) = %q, want none", profile)
+	}
+	if _, ok := ReadProfile(filepath.Join(root, "missing")); ok {
+		t.Error("ReadProfile(missing) reported a profile")
+	}
+}
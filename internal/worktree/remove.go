@@ -5,13 +5,26 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/gkarolyi/mxt/internal/ui"
 )
 
 // Remove deletes a git worktree at the provided path.
 // It attempts git worktree removal first, then falls back to manual cleanup.
+// Locked worktrees are never removed; unlock them first.
 func Remove(worktreePath string) error {
+	locked, reason, err := LockStatus(worktreePath)
+	if err != nil {
+		return err
+	}
+	if locked {
+		if reason != "" {
+			return fmt.Errorf("worktree %s is locked (%s)", worktreePath, reason)
+		}
+		return fmt.Errorf("worktree %s is locked", worktreePath)
+	}
 	cmd := exec.Command("git", "worktree", "remove", worktreePath, "--force")
 	cmd.Stdout = io.Discard
 	cmd.Stderr = io.Discard
@@ -27,3 +40,27 @@ func Remove(worktreePath string) error {
 	}
 	return nil
 }
+
+// Move relocates a git worktree, creating the destination's parent directory.
+// Uses: git worktree move <old> <new>
+func Move(oldPath, newPath string) error {
+	if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	cmd := exec.Command("git", "worktree", "move", oldPath, newPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree move failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Repair fixes the links between the repository and the worktrees at paths,
+// e.g. after they were moved without git worktree move.
+// Uses: git worktree repair <path>...
+func Repair(paths ...string) error {
+	cmd := exec.Command("git", append([]string{"worktree", "repair"}, paths...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree repair failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
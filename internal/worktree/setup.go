@@ -0,0 +1,125 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gkarolyi/mxt/internal/sandbox"
+)
+
+// Pre-session command modes that run inside the tmux session. The default,
+// "block", runs it before the session is created.
+const (
+	SetupModeWindow = "window" // Run in a dedicated "setup" tmux window
+	SetupModeAgent  = "agent"  // Run in the agent window, before the --run command
+)
+
+// setupStatusFile is stored in the worktree's git dir so it never shows up in git status.
+const setupStatusFile = "mxt-setup-status"
+
+// Recorded setup states (see ReadSetupStatus).
+const (
+	SetupRunning  = "running"
+	SetupTimedOut = "timeout"
+)
+
+// timeoutExitCode is reported when the setup command is killed by its timeout,
+// matching coreutils timeout(1).
+const timeoutExitCode = 124
+
+// interruptExitCode is recorded when the setup command is stopped by Ctrl-C,
+// as a shell reports a command killed by SIGINT.
+const interruptExitCode = 130
+
+// preSessionWaitDelay bounds how long RunPreSessionCommand waits for the
+// command's output after killing it.
+const preSessionWaitDelay = 5 * time.Second
+
+// gitDir returns a worktree's private git dir (.git/worktrees/<name> for a
+// linked worktree), where mxt keeps its per-worktree records.
+// Uses: git rev-parse --absolute-git-dir
+func gitDir(worktreePath string) (string, error) {
+	output, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git dir for %s: %w", worktreePath, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SetupStatusPath returns the file where the pre-session command's status is
+// recorded for a worktree.
+func SetupStatusPath(worktreePath string) (string, error) {
+	dir, err := gitDir(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, setupStatusFile), nil
+}
+
+// RecordSetupStatus writes the setup state for a worktree: SetupRunning,
+// SetupTimedOut, or "exit <code>".
+func RecordSetupStatus(worktreePath, status string) error {
+	statusPath, err := SetupStatusPath(worktreePath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statusPath, []byte(status+"\n"), 0o644)
+}
+
+// ReadSetupStatus returns the recorded setup state for a worktree, if any.
+func ReadSetupStatus(worktreePath string) (string, bool) {
+	statusPath, err := SetupStatusPath(worktreePath)
+	if err != nil {
+		return "", false
+	}
+	content, err := os.ReadFile(statusPath)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(content)), true
+}
+
+// ExitStatus formats an exit code for RecordSetupStatus.
+func ExitStatus(code int) string {
+	return fmt.Sprintf("exit %d", code)
+}
+
+// SetupCommandLine builds a shell command line that runs command, records its
+// status in statusPath, and exits with the command's exit code. When timeout is
+// positive the command is terminated after that long and recorded as timed out
+// (exit code 124). The result is meant to be typed into a tmux pane.
+func SetupCommandLine(command, statusPath string, timeout time.Duration) string {
+	status := sandbox.Quote(statusPath)
+
+	steps := []string{fmt.Sprintf("echo %s > %s", SetupRunning, status)}
+	if timeout > 0 {
+		seconds := int(timeout.Round(time.Second) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		// The command runs in its own process group so the timeout terminates
+		// everything it started (e.g. npm's node workers), as RunPreSessionCommand
+		// does. setsid isn't installed on macOS, whose sh (bash) gives a
+		// background job its own group under set -m instead.
+		quoted := sandbox.Quote(command)
+		steps = append(steps,
+			fmt.Sprintf("flag=%s.timeout", status),
+			`rm -f "$flag"`,
+			fmt.Sprintf("if command -v setsid >/dev/null 2>&1; then setsid sh -c %s & else set -m; sh -c %s & fi; pid=$!", quoted, quoted),
+			fmt.Sprintf(`( sleep %d; if kill -0 $pid; then touch "$flag"; kill -TERM -$pid; fi ) >/dev/null 2>&1 & watcher=$!`, seconds),
+			"wait $pid",
+			"code=$?",
+			"kill $watcher 2>/dev/null",
+			fmt.Sprintf(`if [ -f "$flag" ]; then rm -f "$flag"; echo %s > %s; exit %d; fi`, SetupTimedOut, status, timeoutExitCode),
+		)
+	} else {
+		steps = append(steps, fmt.Sprintf("sh -c %s", sandbox.Quote(command)), "code=$?")
+	}
+	steps = append(steps, fmt.Sprintf(`echo "exit $code" > %s`, status), "exit $code")
+
+	return "sh -c " + sandbox.Quote(strings.Join(steps, "; "))
+}
@@ -0,0 +1,52 @@
+package worktree
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSetupCommandLine runs the generated command line and checks the recorded status
+func TestSetupCommandLine(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      string
+		timeout      time.Duration
+		expectedCode int
+		expected     string
+	}{
+		{name: "success", command: "true", expectedCode: 0, expected: "exit 0"},
+		{name: "failure", command: "exit 3", expectedCode: 3, expected: "exit 3"},
+		{name: "success with timeout", command: "true", timeout: time.Minute, expectedCode: 0, expected: "exit 0"},
+		{name: "timed out", command: "sleep 10", timeout: time.Second, expectedCode: timeoutExitCode, expected: SetupTimedOut},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statusPath := filepath.Join(t.TempDir(), "status file")
+			line := SetupCommandLine(tt.command, statusPath, tt.timeout)
+
+			err := exec.Command("sh", "-c", line).Run()
+			code := 0
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("failed to run setup command line: %v", err)
+			}
+			if code != tt.expectedCode {
+				t.Errorf("exit code = %d, want %d", code, tt.expectedCode)
+			}
+
+			content, err := os.ReadFile(statusPath)
+			if err != nil {
+				t.Fatalf("failed to read status file: %v", err)
+			}
+			if status := strings.TrimSpace(string(content)); status != tt.expected {
+				t.Errorf("status = %q, want %q", status, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// NormalizeSparsePaths cleans sparse_paths entries into cone-mode directories,
+// dropping empty entries and leading "./" or "/" and trailing "/".
+func NormalizeSparsePaths(values []string) []string {
+	var paths []string
+	for _, path := range values {
+		path = strings.TrimSpace(path)
+		for strings.HasPrefix(path, "./") {
+			path = strings.TrimPrefix(path, "./")
+		}
+		path = strings.Trim(path, "/")
+		if path != "" && path != "." {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// setupSparseCheckout configures cone-mode sparse checkout in a worktree created
+// with --no-checkout, then checks out the branch. Git writes core.sparseCheckout
+// to the worktree's own config.worktree, so the main checkout keeps every file,
+// but it first sets extensions.worktreeConfig = true in the repository's shared
+// .git/config, which every worktree reads.
+// Uses: git sparse-checkout set --cone -- <paths>; git checkout
+func setupSparseCheckout(ctx context.Context, worktreePath string, sparsePaths []string) error {
+	args := append([]string{"-C", worktreePath, "sparse-checkout", "set", "--cone", "--"}, sparsePaths...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git sparse-checkout set failed: %w", err)
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "-C", worktreePath, "checkout")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git checkout failed: %w", err)
+	}
+	return nil
+}
+
+// SparsePaths returns the cone-mode sparse-checkout directories of a worktree,
+// or nil if the worktree is not sparse.
+// Uses: git sparse-checkout list
+func SparsePaths(worktreePath string) []string {
+	cmd := exec.Command("git", "-C", worktreePath, "sparse-checkout", "list")
+	output, err := cmd.Output()
+	if err != nil {
+		// Exits non-zero with "this worktree is not sparse"
+		return nil
+	}
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,77 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+// TestNormalizeSparsePaths tests normalizing sparse_paths into cone-mode directories
+func TestNormalizeSparsePaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{name: "empty", input: nil, expected: nil},
+		{name: "single", input: []string{"packages/api"}, expected: []string{"packages/api"}},
+		{name: "trims separators", input: []string{" ./packages/api/ ", " /libs/shared ", ""}, expected: []string{"packages/api", "libs/shared"}},
+		{name: "drops root", input: []string{".", "/"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := NormalizeSparsePaths(tt.input); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("NormalizeSparsePaths(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateSparse creates a sparse worktree and checks only the cone is checked out
+func TestCreateSparse(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	repoGit := testutil.NewRepo(t, repo)
+	for _, file := range []string{"README.md", "packages/api/main.go", "packages/web/index.js", "libs/shared/util.go"} {
+		path := filepath.Join(repo, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+	repoGit("add", "-A")
+	repoGit("commit", "-q", "-m", "files")
+	t.Chdir(repo)
+
+	worktreePath := filepath.Join(root, "worktrees", "feature")
+	sparse := []string{"packages/api", "libs/shared"}
+	if err := Create(context.Background(), worktreePath, "feature", "main", sparse); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for file, expected := range map[string]bool{
+		"README.md":             true, // top-level files are always included in cone mode
+		"packages/api/main.go":  true,
+		"libs/shared/util.go":   true,
+		"packages/web/index.js": false,
+	} {
+		_, err := os.Stat(filepath.Join(worktreePath, filepath.FromSlash(file)))
+		if exists := err == nil; exists != expected {
+			t.Errorf("%s exists = %v, want %v", file, exists, expected)
+		}
+	}
+
+	if paths := SparsePaths(worktreePath); !reflect.DeepEqual(paths, []string{"libs/shared", "packages/api"}) {
+		t.Errorf("SparsePaths() = %v, want [libs/shared packages/api]", paths)
+	}
+	if paths := SparsePaths(repo); paths != nil {
+		t.Errorf("SparsePaths(main checkout) = %v, want nil", paths)
+	}
+}
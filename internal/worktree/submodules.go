@@ -0,0 +1,127 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gkarolyi/mxt/internal/ui"
+)
+
+// Submodule is a submodule declared in .gitmodules.
+type Submodule struct {
+	Name string
+	Path string // Relative to the worktree root (slash-separated)
+}
+
+// ListSubmodules returns the submodules declared in a worktree's .gitmodules.
+// Uses: git config --file .gitmodules --get-regexp ^submodule\..*\.path$
+func ListSubmodules(worktreePath string) ([]Submodule, error) {
+	gitmodules := filepath.Join(worktreePath, ".gitmodules")
+	if _, err := os.Stat(gitmodules); err != nil {
+		return nil, nil
+	}
+	cmd := exec.Command("git", "config", "--file", gitmodules, "--get-regexp", `^submodule\..*\.path$`)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			// No matching keys
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+
+	var submodules []Submodule
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Format: submodule.<name>.path <path>
+		key, path, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "submodule."), ".path")
+		submodules = append(submodules, Submodule{Name: name, Path: path})
+	}
+	return submodules, nil
+}
+
+// InitSubmodules initializes and checks out submodules in a new worktree,
+// recursively. When the main checkout at repoRoot already has a submodule
+// checked out, it is passed as --reference so objects are shared instead of
+// cloned again.
+// Uses: git submodule update --init --recursive [--reference <repoRoot>/<path>] -- <path>
+func InitSubmodules(repoRoot, worktreePath string) error {
+	submodules, err := ListSubmodules(worktreePath)
+	if err != nil {
+		return err
+	}
+	if len(submodules) == 0 {
+		return nil
+	}
+
+	ui.Info(fmt.Sprintf("Initializing %d submodule(s)...", len(submodules)))
+	var failed []string
+	for _, submodule := range submodules {
+		args := []string{"-C", worktreePath, "submodule", "update", "--init", "--recursive"}
+		reference := filepath.Join(repoRoot, filepath.FromSlash(submodule.Path))
+		if isCheckoutRoot(reference) {
+			args = append(args, "--reference", reference)
+			fmt.Printf("  %s %s\n", submodule.Path, ui.DimText("(reusing objects from main checkout)"))
+		} else {
+			fmt.Printf("  %s\n", submodule.Path)
+		}
+		args = append(args, "--", submodule.Path)
+
+		cmd := exec.Command("git", args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			failed = append(failed, submodule.Path)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to initialize submodules: %s", strings.Join(failed, ", "))
+	}
+	ui.Success("Submodules initialized")
+	return nil
+}
+
+// isCheckoutRoot reports whether dir is the top level of a git checkout
+// (e.g. an initialized submodule, rather than an empty submodule directory).
+func isCheckoutRoot(dir string) bool {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	toplevel, err := filepath.EvalSymlinks(strings.TrimSpace(string(output)))
+	if err != nil {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	return toplevel == resolved
+}
+
+// PullLFS downloads Git LFS objects for the worktree's checkout and replaces
+// pointer files with their content.
+// Uses: git lfs pull
+func PullLFS(worktreePath string) error {
+	if err := exec.Command("git", "lfs", "version").Run(); err != nil {
+		return fmt.Errorf("git-lfs is not installed")
+	}
+
+	ui.Info("Pulling Git LFS objects...")
+	cmd := exec.Command("git", "-C", worktreePath, "lfs", "pull")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git lfs pull failed: %w", err)
+	}
+	ui.Success("Git LFS objects pulled")
+	return nil
+}
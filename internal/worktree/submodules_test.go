@@ -0,0 +1,70 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkarolyi/mxt/internal/testutil"
+)
+
+// TestInitSubmodules initializes a submodule in a new worktree using the main checkout as reference
+func TestInitSubmodules(t *testing.T) {
+	// Local file:// submodule URLs are blocked by default since git 2.38.1
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	root := t.TempDir()
+	lib := filepath.Join(root, "lib")
+	repo := filepath.Join(root, "app")
+	libGit := testutil.NewRepo(t, lib)
+	if err := os.WriteFile(filepath.Join(lib, "lib.go"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write lib.go: %v", err)
+	}
+	libGit("add", "-A")
+	libGit("commit", "-q", "-m", "lib")
+	repoGit := testutil.NewRepo(t, repo)
+	repoGit("submodule", "add", "-q", lib, "vendor/lib")
+	repoGit("commit", "-q", "-m", "app")
+	t.Chdir(repo)
+
+	worktreePath := filepath.Join(root, "worktrees", "feature")
+	if err := Create(context.Background(), worktreePath, "feature", "main", nil); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	submodules, err := ListSubmodules(worktreePath)
+	if err != nil {
+		t.Fatalf("ListSubmodules() error = %v", err)
+	}
+	if len(submodules) != 1 || submodules[0].Path != "vendor/lib" {
+		t.Fatalf("ListSubmodules() = %v, want [vendor/lib]", submodules)
+	}
+
+	if err := InitSubmodules(repo, worktreePath); err != nil {
+		t.Fatalf("InitSubmodules() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, "vendor", "lib", "lib.go")); err != nil {
+		t.Errorf("submodule not checked out: %v", err)
+	}
+
+	// The submodule borrows objects from the main checkout's copy
+	gitDir := testutil.RunGit(t, filepath.Join(worktreePath, "vendor", "lib"), "rev-parse", "--absolute-git-dir")
+	alternates, err := os.ReadFile(filepath.Join(gitDir, "objects", "info", "alternates"))
+	if err != nil {
+		t.Fatalf("expected alternates file for --reference: %v", err)
+	}
+	if !strings.Contains(string(alternates), filepath.Join("modules", "vendor", "lib")) {
+		t.Errorf("alternates = %q, want reference to the main checkout's submodule", alternates)
+	}
+}
+
+func TestListSubmodulesWithoutGitmodules(t *testing.T) {
+	submodules, err := ListSubmodules(t.TempDir())
+	if err != nil || submodules != nil {
+		t.Errorf("ListSubmodules() = %v, %v; want nil, nil", submodules, err)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gkarolyi/mxt/internal/sandbox"
 	"github.com/gkarolyi/mxt/internal/ui"
@@ -20,12 +23,14 @@ import (
 // Steps:
 //  1. Print info message with worktree path
 //  2. Create parent directory if needed
-//  3. Run: git worktree add -b <branch> <path> <base-branch>
-//  4. Print success message with branch and base branch
+//  3. Run: git worktree add --no-track -b <branch> <path> <base-branch>
+//     (with --no-checkout when sparsePaths is set)
+//  4. For sparse worktrees: configure cone-mode sparse checkout, then check out
+//  5. Print success message with branch and base branch
 //
 // The git output (Preparing worktree, HEAD is now at...) is automatically
 // printed to stdout by the git command.
-func Create(ctx context.Context, worktreePath, branchName, baseBranch string) error {
+func Create(ctx context.Context, worktreePath, branchName, baseBranch string, sparsePaths []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -37,8 +42,14 @@ func Create(ctx context.Context, worktreePath, branchName, baseBranch string) er
 		return fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
-	// Create worktree: git worktree add -b <branch> <path> <base-branch>
-	cmd := exec.CommandContext(ctx, "git", "worktree", "add", "-b", branchName, worktreePath, baseBranch)
+	// Create worktree: git worktree add --no-track -b <branch> <path> <base-branch>
+	// --no-track keeps a branch started from <remote>/<base> from tracking the base branch
+	args := []string{"worktree", "add", "--no-track"}
+	if len(sparsePaths) > 0 {
+		args = append(args, "--no-checkout")
+	}
+	args = append(args, "-b", branchName, worktreePath, baseBranch)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -46,6 +57,14 @@ func Create(ctx context.Context, worktreePath, branchName, baseBranch string) er
 		return fmt.Errorf("git worktree add failed: %w", err)
 	}
 
+	// Sparse worktrees: restrict the checkout to the requested directories
+	if len(sparsePaths) > 0 {
+		if err := setupSparseCheckout(ctx, worktreePath, sparsePaths); err != nil {
+			return err
+		}
+		ui.Info(fmt.Sprintf("Sparse checkout: %s", strings.Join(sparsePaths, ", ")))
+	}
+
 	// Success message with colored branch names
 	branchColored := ui.CyanText(branchName)
 	baseColored := ui.DimText("from " + baseBranch)
@@ -54,72 +73,64 @@ func Create(ctx context.Context, worktreePath, branchName, baseBranch string) er
 	return nil
 }
 
-// CopyFiles copies files from source directory to worktree directory.
-// The copyFiles parameter is a comma-separated list of file patterns (supports globs).
+// AddExisting creates a worktree at worktreePath for a branch that already
+// exists, creating the parent directory first. If the branch exists only on a
+// remote, git creates a local branch tracking it.
+// Uses: git worktree add <path> <branch>
+func AddExisting(worktreePath, branchName string) error {
+	ui.Info(fmt.Sprintf("Creating worktree at %s", worktreePath))
+
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	cmd := exec.Command("git", "worktree", "add", worktreePath, branchName)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git worktree add failed: %w", err)
+	}
+	return nil
+}
+
+// CopyFiles copies the files selected by a CopyPlan from source directory to
+// worktree directory. Build the plan with BuildCopyPlan (copy_files globs, '**'
+// and '!' exclusions, plus git-ignored files selected by copy_ignored).
 //
-// For each pattern:
-//  1. Trim whitespace
-//  2. Expand glob relative to source directory
-//  3. If no matches, print warning and continue
-//  4. For each matched file, copy preserving attributes
-//  5. Print success message for each copied file
+// For each planned file:
+//  1. Create the destination parent directory if needed
+//  2. Copy the file preserving permissions
+//  3. Print success message for each copied file
 //
 // Returns error only if critical failure occurs. Missing files generate warnings but don't fail.
-func CopyFiles(sourceDir, destDir, copyFiles string) error {
+func CopyFiles(sourceDir, destDir string, plan CopyPlan) error {
 	ui.Info("Copying config files...")
 
-	// Split by comma and process each pattern
-	patterns := strings.Split(copyFiles, ",")
+	for _, pattern := range plan.Invalid {
+		ui.Warn(fmt.Sprintf("  Invalid pattern: %s", ui.DimText(pattern)))
+	}
+	for _, pattern := range plan.Missing {
+		ui.Warn(fmt.Sprintf("  Not found: %s", ui.DimText(pattern)))
+	}
 
-	for _, pattern := range patterns {
-		pattern = strings.TrimSpace(pattern)
-		if pattern == "" {
-			continue
-		}
+	for _, relPath := range plan.Files {
+		srcPath := filepath.Join(sourceDir, filepath.FromSlash(relPath))
+		dstPath := filepath.Join(destDir, filepath.FromSlash(relPath))
 
-		// Expand glob pattern relative to source directory
-		hasGlob := strings.ContainsAny(pattern, "*?[")
-		fullPattern := filepath.Join(sourceDir, pattern)
-		matches, err := filepath.Glob(fullPattern)
-		if err != nil {
-			ui.Warn(fmt.Sprintf("  Invalid pattern: %s", ui.DimText(pattern)))
+		// Create destination parent directory if needed
+		dstParent := filepath.Dir(dstPath)
+		if err := os.MkdirAll(dstParent, 0o755); err != nil {
+			ui.Warn(fmt.Sprintf("  Failed to create directory for %s", ui.DimText(relPath)))
 			continue
 		}
 
-		if len(matches) == 0 {
-			if hasGlob {
-				ui.Warn(fmt.Sprintf("  Not found: %s", ui.DimText(pattern)))
-			}
+		// Copy file
+		if err := copyFile(srcPath, dstPath); err != nil {
+			ui.Warn(fmt.Sprintf("  Failed to copy %s: %v", ui.DimText(relPath), err))
 			continue
 		}
 
-		// Copy each matched file
-		for _, srcPath := range matches {
-			// Calculate relative path from source directory
-			relPath, err := filepath.Rel(sourceDir, srcPath)
-			if err != nil {
-				ui.Warn(fmt.Sprintf("  Failed to calculate relative path for %s", ui.DimText(srcPath)))
-				continue
-			}
-
-			// Calculate destination path
-			dstPath := filepath.Join(destDir, relPath)
-
-			// Create destination parent directory if needed
-			dstParent := filepath.Dir(dstPath)
-			if err := os.MkdirAll(dstParent, 0o755); err != nil {
-				ui.Warn(fmt.Sprintf("  Failed to create directory for %s", ui.DimText(relPath)))
-				continue
-			}
-
-			// Copy file
-			if err := copyFile(srcPath, dstPath); err != nil {
-				ui.Warn(fmt.Sprintf("  Failed to copy %s: %v", ui.DimText(relPath), err))
-				continue
-			}
-
-			ui.Success(fmt.Sprintf("  Copied %s", ui.DimText(relPath)))
-		}
+		ui.Success(fmt.Sprintf("  Copied %s", ui.DimText(relPath)))
 	}
 
 	return nil
@@ -127,9 +138,13 @@ func CopyFiles(sourceDir, destDir, copyFiles string) error {
 
 type PreSessionError struct {
 	ExitCode int
+	TimedOut bool
 }
 
 func (e PreSessionError) Error() string {
+	if e.TimedOut {
+		return "timed out"
+	}
 	return fmt.Sprintf("exit code: %d", e.ExitCode)
 }
 
@@ -139,32 +154,57 @@ func (e PreSessionError) Error() string {
 //  1. Print info message
 //  2. Print command (indented, dimmed)
 //  3. Change to worktree directory
-//  4. Execute command via shell
-//  5. If success, print success message
-//  6. If failure, return error with exit code
+//  4. Execute command via shell (its process group is killed after timeout,
+//     if positive, or on interrupt)
+//  5. Record the exit status (see ReadSetupStatus)
+//  6. If success, print success message
+//  7. If failure, return error with exit code
 //
 // Returns error if command fails. The caller should handle the error by
 // prompting the user for confirmation.
-func RunPreSessionCommand(worktreePath, command, sandboxTool string) error {
+func RunPreSessionCommand(worktreePath, command, sandboxTool string, timeout time.Duration) error {
 	ui.Info("Running pre-session command...")
 
 	// Print the command being run (indented and dimmed)
 	fmt.Printf("  %s\n", ui.DimText(command))
 
+	// The command runs in its own process group, which doesn't get the
+	// terminal's Ctrl-C, so interrupts cancel ctx to kill it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Execute command in worktree directory using shell
-	cmd := sandbox.Command(sandboxTool, "sh", "-c", command)
+	cmd := sandbox.CommandContext(ctx, sandboxTool, "sh", "-c", command)
 	cmd.Dir = worktreePath
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = preSessionWaitDelay
 
+	_ = RecordSetupStatus(worktreePath, SetupRunning)
 	if err := cmd.Run(); err != nil {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			_ = RecordSetupStatus(worktreePath, SetupTimedOut)
+			return PreSessionError{ExitCode: timeoutExitCode, TimedOut: true}
+		case context.Canceled:
+			_ = RecordSetupStatus(worktreePath, ExitStatus(interruptExitCode))
+			return fmt.Errorf("pre-session command interrupted")
+		}
 		// Extract exit code if possible
 		if exitErr, ok := err.(*exec.ExitError); ok {
+			_ = RecordSetupStatus(worktreePath, ExitStatus(exitErr.ExitCode()))
 			return PreSessionError{ExitCode: exitErr.ExitCode()}
 		}
 		return fmt.Errorf("pre-session command failed: %w", err)
 	}
 
+	_ = RecordSetupStatus(worktreePath, ExitStatus(0))
 	ui.Success("Pre-session command completed")
 	return nil
 }
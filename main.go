@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/gkarolyi/mxt/internal/commands"
+	"github.com/gkarolyi/mxt/internal/config"
 	mxtErrors "github.com/gkarolyi/mxt/internal/errors"
 	"github.com/gkarolyi/mxt/internal/ui"
 	"github.com/spf13/cobra"
@@ -28,6 +29,14 @@ Tmux Worktree Session Manager v` + version + `
 A tool for managing git worktrees paired with tmux sessions.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		overrides, _ := cmd.Flags().GetStringArray("set")
+		if err := config.SetFlagOverrides(overrides); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+		config.SetTrustPrompt(commands.PromptTrust)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		showVersion, _ := cmd.Flags().GetBool("version")
 		if showVersion {
@@ -47,14 +56,34 @@ var versionCmd = &cobra.Command{
 }
 
 var initCmd = &cobra.Command{
-	Use:   "init [--local] [--import] [--reinit]",
+	Use:   "init [--local] [--import] [--reinit] [--yes] [--<setting> value]...",
 	Short: "Set up configuration",
-	Long:  "Create global config (~/.config/mxt/config.toml) or project config (.mxt.toml in repo root)",
+	Long: `Create global config (~/.config/mxt/config.toml) or project config (.mxt.toml in repo root).
+
+Each prompt has a flag. Giving any of them, or --yes, skips the prompts:
+the rest take their defaults, or with --local what init detects in the repo.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		local, _ := cmd.Flags().GetBool("local")
 		reinit, _ := cmd.Flags().GetBool("reinit")
 		importLegacy, _ := cmd.Flags().GetBool("import")
-		if err := commands.InitCommand(local, reinit, importLegacy); err != nil {
+		yes, _ := cmd.Flags().GetBool("yes")
+		answer := func(name string) *string {
+			if !cmd.Flags().Changed(name) {
+				return nil
+			}
+			value, _ := cmd.Flags().GetString(name)
+			return &value
+		}
+		answers := commands.InitAnswers{
+			WorktreeDir:   answer("worktree-dir"),
+			Terminal:      answer("terminal"),
+			SandboxTool:   answer("sandbox-tool"),
+			CopyFiles:     answer("copy-files"),
+			PreSessionCmd: answer("pre-session-cmd"),
+			TmuxLayout:    answer("tmux-layout"),
+			Defaults:      yes,
+		}
+		if err := commands.InitCommand(local, reinit, importLegacy, answers); err != nil {
 			ui.Error(err.Error())
 			os.Exit(1)
 		}
@@ -65,6 +94,15 @@ var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Show current configuration",
 	Run: func(cmd *cobra.Command, args []string) {
+		if effective, _ := cmd.Flags().GetBool("effective"); effective {
+			branch, _ := cmd.Flags().GetString("branch")
+			profile, _ := cmd.Flags().GetString("profile")
+			if err := commands.ConfigEffectiveCommand(branch, profile); err != nil {
+				ui.Error(err.Error())
+				os.Exit(1)
+			}
+			return
+		}
 		if err := commands.ConfigCommand(); err != nil {
 			var notFound mxtErrors.ErrConfigNotFound
 			if errors.As(err, &notFound) {
@@ -76,6 +114,66 @@ var configCmd = &cobra.Command{
 	},
 }
 
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a key from the config file",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			ui.Error("Usage: mxt config get <key> [--local]")
+			os.Exit(1)
+		}
+		local, _ := cmd.Flags().GetBool("local")
+		if err := commands.ConfigGetCommand(args[0], local); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a key in the config file, keeping comments",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			ui.Error("Usage: mxt config set <key> <value> [--local]")
+			os.Exit(1)
+		}
+		local, _ := cmd.Flags().GetBool("local")
+		if err := commands.ConfigSetCommand(args[0], args[1], local); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a key from the config file",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			ui.Error("Usage: mxt config unset <key> [--local]")
+			os.Exit(1)
+		}
+		local, _ := cmd.Flags().GetBool("local")
+		if err := commands.ConfigUnsetCommand(args[0], local); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in $VISUAL or $EDITOR",
+	Run: func(cmd *cobra.Command, args []string) {
+		local, _ := cmd.Flags().GetBool("local")
+		if err := commands.ConfigEditCommand(local); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
 var newCmd = &cobra.Command{
 	Use:   "new [branch-name]",
 	Short: "Create worktree + tmux session",
@@ -90,7 +188,7 @@ var newCmd = &cobra.Command{
 				}
 				branchName = prompted
 			} else {
-				ui.Error("Usage: mxt new [branch-name] [--from <base-branch>] [--run claude|codex] [--bg]")
+				ui.Error("Usage: mxt new [branch-name] [--from <base-branch>] [--run claude|codex] [--sparse <dirs>] [--profile <name>] [--bg] [--dry-run]")
 				os.Exit(1)
 			}
 		} else {
@@ -98,9 +196,12 @@ var newCmd = &cobra.Command{
 		}
 		fromBranch, _ := cmd.Flags().GetString("from")
 		runCmd, _ := cmd.Flags().GetString("run")
+		sparse, _ := cmd.Flags().GetString("sparse")
+		profile, _ := cmd.Flags().GetString("profile")
 		bg, _ := cmd.Flags().GetBool("bg")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-		if err := commands.NewCommand(branchName, fromBranch, runCmd, bg); err != nil {
+		if err := commands.NewCommand(branchName, fromBranch, runCmd, sparse, profile, bg, dryRun); err != nil {
 			ui.Error(err.Error())
 			os.Exit(1)
 		}
@@ -112,7 +213,9 @@ var listCmd = &cobra.Command{
 	Aliases: []string{"ls"},
 	Short:   "List worktrees, diff stats, session status",
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := commands.ListCommand(); err != nil {
+		allWorktrees, _ := cmd.Flags().GetBool("all-worktrees")
+		global, _ := cmd.Flags().GetBool("global")
+		if err := commands.ListCommand(allWorktrees, global); err != nil {
 			ui.Error(err.Error())
 			os.Exit(1)
 		}
@@ -136,6 +239,162 @@ var deleteCmd = &cobra.Command{
 	},
 }
 
+var renameCmd = &cobra.Command{
+	Use:     "rename <old-branch> <new-branch>",
+	Aliases: []string{"mv"},
+	Short:   "Rename branch, worktree and session together",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			ui.Error("Usage: mxt rename <old-branch> <new-branch>")
+			os.Exit(1)
+		}
+		if err := commands.RenameCommand(args[0], args[1]); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock <branch|path>",
+	Short: "Lock a worktree so it can't be deleted or pruned",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			ui.Error("Usage: mxt lock <branch|path> [--reason <text>]")
+			os.Exit(1)
+		}
+		reason, _ := cmd.Flags().GetString("reason")
+		if err := commands.LockCommand(args[0], reason); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock <branch|path>",
+	Short: "Unlock a locked worktree",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			ui.Error("Usage: mxt unlock <branch|path>")
+			os.Exit(1)
+		}
+		if err := commands.UnlockCommand(args[0]); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check tools, config and worktrees for problems",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := commands.DoctorCommand(); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Allow the commands in this repository's .mxt.toml to run",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		revoke, _ := cmd.Flags().GetBool("revoke")
+		if err := commands.TrustCommand(revoke); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var jumpCmd = &cobra.Command{
+	Use:   "jump",
+	Short: "Pick any running mxt session with fzf and attach to it",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := commands.JumpCommand(); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <path|branch>",
+	Short: "Bring an existing worktree or branch under mxt management",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			ui.Error("Usage: mxt adopt <path|branch>")
+			os.Exit(1)
+		}
+		if err := commands.AdoptCommand(args[0]); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var migrateDirCmd = &cobra.Command{
+	Use:   "migrate-dir",
+	Short: "Move worktrees into the current worktree_dir",
+	Run: func(cmd *cobra.Command, args []string) {
+		fromDir, _ := cmd.Flags().GetString("from")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if err := commands.MigrateDirCommand(fromDir, dryRun); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var finishCmd = &cobra.Command{
+	Use:   "finish <branch-name>",
+	Short: "Merge a worktree's branch into its base, then delete it",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			ui.Error("Usage: mxt finish <branch-name> [--squash|--rebase|--merge] [--into <base-branch>] [--force|-f]")
+			os.Exit(1)
+		}
+		strategy := "merge"
+		for _, name := range []string{"squash", "rebase", "merge"} {
+			if enabled, _ := cmd.Flags().GetBool(name); enabled {
+				strategy = name
+			}
+		}
+		into, _ := cmd.Flags().GetString("into")
+		force, _ := cmd.Flags().GetBool("force")
+		if err := commands.FinishCommand(args[0], strategy, into, force); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <branch-name>",
+	Short: "Diff a worktree against its base branch",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			ui.Error("Usage: mxt diff <branch-name> [--stat|--name-only] [--tool <difftool>]")
+			os.Exit(1)
+		}
+		format := ""
+		if stat, _ := cmd.Flags().GetBool("stat"); stat {
+			format = "stat"
+		}
+		if nameOnly, _ := cmd.Flags().GetBool("name-only"); nameOnly {
+			format = "name-only"
+		}
+		tool, _ := cmd.Flags().GetString("tool")
+		if err := commands.DiffCommand(args[0], format, tool); err != nil {
+			ui.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
 var sessionsCmd = &cobra.Command{
 	Use:   "sessions <action> <branch-name>",
 	Short: "Manage tmux session for a worktree",
@@ -145,6 +404,9 @@ var sessionsCmd = &cobra.Command{
   relaunch <branch> [--run cmd] Close + reopen session
   attach <branch> [dev|agent]   Attach to session (optionally select window)
 
+  open and relaunch use the profile the worktree was created with;
+  --profile switches it.
+
   Omit <branch> to select interactively when running in a TTY.`,
 	Args: cobra.MinimumNArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -173,6 +435,7 @@ var sessionsCmd = &cobra.Command{
 		}
 
 		runCmd, _ := cmd.Flags().GetString("run")
+		profile, _ := cmd.Flags().GetString("profile")
 		bg, _ := cmd.Flags().GetBool("bg")
 
 		// Pass windowName as runCmd for attach action (reusing parameter)
@@ -180,7 +443,7 @@ var sessionsCmd = &cobra.Command{
 			runCmd = windowName
 		}
 
-		if err := commands.SessionsCommand(action, branchName, runCmd, bg); err != nil {
+		if err := commands.SessionsCommand(action, branchName, runCmd, profile, bg); err != nil {
 			ui.Error(err.Error())
 			os.Exit(1)
 		}
@@ -196,21 +459,76 @@ var helpCmd = &cobra.Command{
 }
 
 func init() {
+	// Add global flags
+	rootCmd.PersistentFlags().StringArrayP("set", "c", nil, "Override a config key for this run (key=value, repeatable)")
+
 	// Add flags for init command
 	initCmd.Flags().BoolP("local", "l", false, "Create project config (.mxt.toml in repo root)")
 	initCmd.Flags().Bool("import", false, "Import legacy key=value config to TOML")
 	initCmd.Flags().Bool("reinit", false, "Overwrite existing config without prompting")
+	initCmd.Flags().BoolP("yes", "y", false, "Accept every default (with --local, what init detects) without prompting")
+	initCmd.Flags().String("worktree-dir", "", "Worktree base directory (global config only)")
+	initCmd.Flags().String("terminal", "", "Terminal app: terminal, iterm2, ghostty or current (global config only)")
+	initCmd.Flags().String("sandbox-tool", "", "Sandbox tool command prefix")
+	initCmd.Flags().String("copy-files", "", "Comma-separated files to copy into new worktrees")
+	initCmd.Flags().String("pre-session-cmd", "", "Command to run after worktree setup, before the tmux session")
+	initCmd.Flags().String("tmux-layout", "", "Tmux layout on one line, e.g. dev:hx|lazygit,agent:")
+
+	// Add flags for trust command
+	trustCmd.Flags().Bool("revoke", false, "Stop trusting the project config")
+
+	// Add flags and subcommands for config command
+	configCmd.Flags().Bool("effective", false, "Show the merged config and where each value comes from")
+	configCmd.Flags().String("branch", "", "Apply the [[rules]] matching this branch with --effective")
+	configCmd.Flags().String("profile", "", "Apply this profile with --effective (default: default_profile)")
+	for _, sub := range []*cobra.Command{configGetCmd, configSetCmd, configUnsetCmd, configEditCmd} {
+		sub.Flags().BoolP("local", "l", false, "Use the project config (.mxt.toml in repo root)")
+	}
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configEditCmd)
 
 	// Add flags for new command
-	newCmd.Flags().String("from", "", "Base branch (default: main/master)")
+	newCmd.Flags().String("from", "", "Base branch (default: base_branch, or <remote>/HEAD)")
 	newCmd.Flags().String("run", "", "Auto-run command in agent window (claude|codex)")
+	newCmd.Flags().String("sparse", "", "Comma-separated directories for a sparse checkout (default: sparse_paths)")
+	newCmd.Flags().String("profile", "", "Apply a [profiles.<name>] table from config (default: default_profile)")
 	newCmd.Flags().Bool("bg", false, "Create session without opening terminal")
+	newCmd.Flags().Bool("dry-run", false, "Show the worktree path and files to copy without creating anything")
+
+	// Add flags for list command
+	listCmd.Flags().Bool("all-worktrees", false, "Also show the main checkout and worktrees not managed by mxt")
+	listCmd.Flags().BoolP("global", "g", false, "Show worktrees of every repository under worktree_dir")
+	listCmd.MarkFlagsMutuallyExclusive("all-worktrees", "global")
 
 	// Add flags for delete command
 	deleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
 
+	// Add flags for migrate-dir command
+	migrateDirCmd.Flags().String("from", "", "Previous worktree_dir (default: any <dir>/<repo>/<branch> worktree)")
+	migrateDirCmd.Flags().Bool("dry-run", false, "Show the moves without making them")
+
+	// Add flags for lock command
+	lockCmd.Flags().String("reason", "", "Why the worktree is locked (shown by mxt list)")
+
+	// Add flags for finish command
+	finishCmd.Flags().Bool("squash", false, "Squash the branch into a single commit on the base branch")
+	finishCmd.Flags().Bool("rebase", false, "Rebase the branch onto the base branch, then fast-forward")
+	finishCmd.Flags().Bool("merge", false, "Create a merge commit (default)")
+	finishCmd.Flags().String("into", "", "Branch to integrate into (default: base_branch, or <remote>/HEAD)")
+	finishCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	finishCmd.MarkFlagsMutuallyExclusive("squash", "rebase", "merge")
+
+	// Add flags for diff command
+	diffCmd.Flags().Bool("stat", false, "Show a diffstat instead of the patch")
+	diffCmd.Flags().Bool("name-only", false, "Show only the names of changed files")
+	diffCmd.Flags().String("tool", "", "Open the changes with git difftool using this tool")
+	diffCmd.MarkFlagsMutuallyExclusive("stat", "name-only")
+
 	// Add flags for sessions command
 	sessionsCmd.Flags().String("run", "", "Auto-run command in agent window (claude|codex)")
+	sessionsCmd.Flags().String("profile", "", "Apply a config profile (default: the one the worktree was created with)")
 	sessionsCmd.Flags().Bool("bg", false, "Create session without opening terminal")
 
 	// Add subcommands to root
@@ -220,7 +538,17 @@ func init() {
 	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(adoptCmd)
+	rootCmd.AddCommand(migrateDirCmd)
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(unlockCmd)
+	rootCmd.AddCommand(finishCmd)
+	rootCmd.AddCommand(diffCmd)
 	rootCmd.AddCommand(sessionsCmd)
+	rootCmd.AddCommand(jumpCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(trustCmd)
 	rootCmd.AddCommand(helpCmd)
 	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
 		commands.HelpCommand(version)